@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"t/internal/runner"
+	"t/internal/ui"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var atCmd = &cobra.Command{
+	Use:   ":at <duration|time> <task-name>",
+	Short: "Run a task later, in the background",
+	Long:  "Schedule a task to run after a delay (5m, 1h30m) or at a time (RFC3339, or '2006-01-02 15:04:05' local time). A background process waits and then runs the task, so closing the terminal doesn't cancel it. The pending run shows up in ':ps' and ':stop' cancels it; its output goes to a log file like a detached task's.",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		when, taskName := args[0], args[1]
+
+		at, err := parseAt(when)
+		if err != nil {
+			fmt.Fprintf(ui.Stdout, "❌ %v\n", err)
+			os.Exit(1)
+		}
+
+		config, err := loadConfig()
+		if err != nil {
+			fmt.Fprintf(ui.Stdout, "❌ Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+
+		self, err := os.Executable()
+		if err != nil {
+			fmt.Fprintf(ui.Stdout, "❌ Cannot schedule tasks: %v\n", err)
+			os.Exit(1)
+		}
+		argv := append([]string{self}, inheritedFlags(cmd)...)
+		argv = append(argv, ":run-at", at.Format(time.RFC3339), taskName)
+
+		taskRunner := newRunner(config)
+		proc, err := taskRunner.ScheduleTask(taskName, at, argv)
+		if err != nil {
+			fmt.Fprintf(ui.Stdout, "❌ Failed to schedule task: %v\n", err)
+			if errors.Is(err, runner.ErrTaskNotFound) {
+				fmt.Fprintln(ui.Stdout, "\n💡 Use 't :list' to see available tasks")
+			}
+			os.Exit(1)
+		}
+
+		fmt.Fprintf(ui.Stdout, "⏳ Task '%s' scheduled for %s (in %v)\n", taskName, at.Format("2006-01-02 15:04:05"), time.Until(at).Round(time.Second))
+		fmt.Fprintf(ui.Stdout, "📝 Logs: %s\n", proc.LogFile)
+		fmt.Fprintf(ui.Stdout, "🛑 Cancel with: t :stop %d\n", proc.PID)
+	},
+}
+
+// runAtCmd is the background process started by :at. It waits until the
+// scheduled time and runs the task. Nobody can answer prompts by then, so
+// tasks that would prompt fail.
+var runAtCmd = &cobra.Command{
+	Use:    ":run-at <time> <task-name>",
+	Short:  "Wait until a time, then run a task",
+	Hidden: true,
+	Args:   cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		at, err := time.Parse(time.RFC3339, args[0])
+		if err != nil {
+			fmt.Fprintf(ui.Stdout, "❌ Invalid time: %v\n", err)
+			os.Exit(1)
+		}
+		taskName := args[1]
+
+		fmt.Fprintf(ui.Stdout, "⏳ Waiting until %s to run task '%s'\n", at.Format(time.RFC3339), taskName)
+		time.Sleep(time.Until(at))
+
+		// The config is read now, so edits made while waiting are used
+		config, err := loadConfig()
+		if err != nil {
+			fmt.Fprintf(ui.Stdout, "❌ Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+
+		taskRunner := newRunner(config)
+		taskRunner.AssumeYes, _ = cmd.Flags().GetBool("yes")
+		taskRunner.NonInteractive = true
+		if err := taskRunner.RunTask(taskName); err != nil {
+			fmt.Fprintf(ui.Stdout, "❌ Task failed: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		fmt.Fprintf(ui.Stdout, "🎉 Task '%s' completed successfully!\n", taskName)
+	},
+}
+
+// parseAt turns an :at argument into the time to run at: a duration from
+// now, or an RFC3339 or "2006-01-02 15:04:05" local timestamp in the future
+func parseAt(value string) (time.Time, error) {
+	if delay, err := time.ParseDuration(value); err == nil {
+		if delay < 0 {
+			return time.Time{}, fmt.Errorf("delay %s is negative", value)
+		}
+		return time.Now().Add(delay), nil
+	}
+
+	at, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		at, err = time.ParseInLocation("2006-01-02 15:04:05", value, time.Local)
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q, use a duration like 5m or a timestamp like 2006-01-02T15:04:05Z", value)
+	}
+	if at.Before(time.Now()) {
+		return time.Time{}, fmt.Errorf("time %s is in the past", value)
+	}
+	return at, nil
+}
+
+// inheritedFlags returns the global flags given to this invocation, for a
+// t process started on its behalf. --chdir is left out since the process
+// starts in the directory it already changed to.
+func inheritedFlags(cmd *cobra.Command) []string {
+	var flags []string
+	cmd.InheritedFlags().Visit(func(flag *pflag.Flag) {
+		if flag.Name == "chdir" {
+			return
+		}
+		if slice, ok := flag.Value.(pflag.SliceValue); ok {
+			for _, value := range slice.GetSlice() {
+				flags = append(flags, "--"+flag.Name+"="+value)
+			}
+			return
+		}
+		flags = append(flags, "--"+flag.Name+"="+flag.Value.String())
+	})
+	return flags
+}
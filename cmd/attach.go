@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"t/internal/runner"
+	"t/internal/ui"
+
+	"github.com/spf13/cobra"
+)
+
+var attachCmd = &cobra.Command{
+	Use:     ":attach <task-name-or-pid>",
+	Aliases: []string{":a", ":fg", ":foreground"},
+	Short:   "Attach to a detached task",
+	Long:    "Follow the logs of a detached task in the foreground. Press Ctrl+C to stop the task, or type 'q' and press Enter to detach again and leave it running.",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		identifier := args[0]
+
+		// Load config (we need a runner instance)
+		config, err := loadConfig()
+		if err != nil {
+			config = &runner.Config{} // Empty config
+		}
+
+		taskRunner := newRunner(config)
+
+		proc, err := taskRunner.FindDetachedProcess(identifier)
+		if err != nil {
+			fmt.Fprintf(ui.Stdout, "❌ %v\n", err)
+			fmt.Fprintln(ui.Stdout, "\n💡 Use 't :ps' to see running detached tasks")
+			return
+		}
+
+		fmt.Fprintf(ui.Stdout, "🔗 Attached to task '%s' (PID: %d)\n", proc.TaskName, proc.PID)
+		fmt.Fprintln(ui.Stdout, "💡 Press Ctrl+C to stop the task, type 'q' + Enter to detach")
+		fmt.Fprintln(ui.Stdout, "─────────────────────────────────────────────")
+
+		// Forward Ctrl+C to the detached process instead of exiting
+		interrupts := make(chan os.Signal, 1)
+		signal.Notify(interrupts, os.Interrupt)
+		defer signal.Stop(interrupts)
+
+		detach := make(chan struct{})
+		go func() {
+			scanner := bufio.NewScanner(os.Stdin)
+			for scanner.Scan() {
+				if strings.TrimSpace(scanner.Text()) == "q" {
+					close(detach)
+					return
+				}
+			}
+		}()
+
+		done := make(chan struct{})
+		defer close(done)
+		go func() {
+			if err := followLog(proc.LogFile, os.Stdout, done); err != nil {
+				fmt.Fprintf(ui.Stdout, "❌ Error following logs: %v\n", err)
+			}
+		}()
+
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-interrupts:
+				fmt.Fprintln(ui.Stdout)
+				if err := taskRunner.StopDetachedProcess(strconv.Itoa(proc.PID), runner.DefaultStopGrace); err != nil {
+					fmt.Fprintf(ui.Stdout, "❌ Error stopping process: %v\n", err)
+				}
+				return
+			case <-detach:
+				fmt.Fprintf(ui.Stdout, "👋 Detached from task '%s', it keeps running in the background (PID: %d)\n", proc.TaskName, proc.PID)
+				return
+			case <-ticker.C:
+				if _, err := taskRunner.FindDetachedProcess(strconv.Itoa(proc.PID)); err != nil {
+					fmt.Fprintf(ui.Stdout, "🏁 Task '%s' has exited\n", proc.TaskName)
+					return
+				}
+			}
+		}
+	},
+}
+
+// followLog prints the last lines of a log file and keeps streaming new
+// output to out until done is closed
+func followLog(path string, out io.Writer, done <-chan struct{}) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return err
+	}
+
+	lines := strings.SplitAfter(string(data), "\n")
+	if len(lines) > 50 {
+		lines = lines[len(lines)-50:]
+	}
+	fmt.Fprint(out, strings.Join(lines, ""))
+
+	return followFrom(file, out, done)
+}
+
+// followFrom copies data appended to file after its current offset to out
+// until done is closed
+func followFrom(file *os.File, out io.Writer, done <-chan struct{}) error {
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return nil
+		case <-ticker.C:
+			if _, err := io.Copy(out, file); err != nil {
+				return err
+			}
+		}
+	}
+}
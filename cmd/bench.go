@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"t/internal/runner"
+	"t/internal/ui"
+
+	"github.com/spf13/cobra"
+)
+
+// The histogram of :bench has at most benchBuckets bars, the longest being
+// benchBarWidth characters
+const (
+	benchBuckets  = 8
+	benchBarWidth = 30
+)
+
+var benchCmd = &cobra.Command{
+	Use:   ":bench <task-name>",
+	Short: "Run a task several times and report timing statistics",
+	Long:  "Run a task --runs times, after --warmup untimed runs, and report the min, median, mean, max and standard deviation of the durations with a histogram. Each run starts afresh, so the task and its dependencies run every time.",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		taskName := args[0]
+		runs, _ := cmd.Flags().GetInt("runs")
+		warmup, _ := cmd.Flags().GetInt("warmup")
+		csvPath, _ := cmd.Flags().GetString("csv")
+
+		if runs < 1 || warmup < 0 {
+			fmt.Fprintln(ui.Stdout, "❌ --runs must be at least 1 and --warmup can't be negative")
+			os.Exit(1)
+		}
+
+		config, err := loadConfig()
+		if err != nil {
+			fmt.Fprintf(ui.Stdout, "❌ Error loading config: %v\n", err)
+			fmt.Fprintln(ui.Stdout, "\n💡 Tip: Run 't :init' to create a tasks.yaml file")
+			os.Exit(1)
+		}
+
+		samples, err := newRunner(config).BenchTask(taskName, runs, warmup)
+		if err != nil {
+			fmt.Fprintf(ui.Stdout, "❌ Benchmark failed: %v\n", err)
+			os.Exit(1)
+		}
+
+		if csvPath != "" {
+			if err := writeBenchCSV(csvPath, samples); err != nil {
+				fmt.Fprintf(ui.Stdout, "❌ Error writing samples: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		stats := runner.NewBenchStats(samples)
+		fmt.Fprintln(ui.Stdout)
+		fmt.Fprintf(ui.Stdout, "📊 %s over %d run(s):\n", taskName, len(samples))
+		fmt.Fprintf(ui.Stdout, "  min     %v\n", stats.Min.Round(time.Millisecond))
+		fmt.Fprintf(ui.Stdout, "  median  %v\n", stats.Median.Round(time.Millisecond))
+		fmt.Fprintf(ui.Stdout, "  mean    %v\n", stats.Mean.Round(time.Millisecond))
+		fmt.Fprintf(ui.Stdout, "  max     %v\n", stats.Max.Round(time.Millisecond))
+		fmt.Fprintf(ui.Stdout, "  stddev  %v\n", stats.Stddev.Round(time.Millisecond))
+		printBenchHistogram(samples, stats)
+		if csvPath != "" {
+			fmt.Fprintf(ui.Stdout, "📄 Samples written to %s\n", csvPath)
+		}
+	},
+}
+
+func init() {
+	benchCmd.Flags().Int("runs", 10, "Number of timed runs")
+	benchCmd.Flags().Int("warmup", 0, "Number of untimed runs before the timed ones")
+	benchCmd.Flags().String("csv", "", "Write the duration of each timed run to this CSV file")
+}
+
+// printBenchHistogram prints how the samples spread between the min and max,
+// one bar per equal-width bucket
+func printBenchHistogram(samples []time.Duration, stats runner.BenchStats) {
+	buckets := benchBuckets
+	if len(samples) < buckets {
+		buckets = len(samples)
+	}
+	width := (stats.Max - stats.Min) / time.Duration(buckets)
+	if width <= 0 {
+		// Every run took the same time
+		return
+	}
+
+	counts := make([]int, buckets)
+	most := 0
+	for _, sample := range samples {
+		bucket := min(int((sample-stats.Min)/width), buckets-1)
+		counts[bucket]++
+		most = max(most, counts[bucket])
+	}
+
+	fmt.Fprintln(ui.Stdout)
+	for i, count := range counts {
+		bar := count * benchBarWidth / most
+		if count > 0 {
+			bar = max(bar, 1)
+		}
+		from := stats.Min + time.Duration(i)*width
+		fmt.Fprintf(ui.Stdout, "  %-10v %-*s %d\n", from.Round(time.Millisecond), benchBarWidth, strings.Repeat("#", bar), count)
+	}
+}
+
+// writeBenchCSV writes one row per timed run with its duration in milliseconds
+func writeBenchCSV(path string, samples []time.Duration) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	w.Write([]string{"run", "duration_ms"})
+	for i, sample := range samples {
+		ms := float64(sample) / float64(time.Millisecond)
+		w.Write([]string{strconv.Itoa(i + 1), strconv.FormatFloat(ms, 'f', 3, 64)})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	return file.Close()
+}
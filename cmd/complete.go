@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"t/internal/runner"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	stopCmd.ValidArgsFunction = completeDetached
+	logsCmd.ValidArgsFunction = completeDetached
+	attachCmd.ValidArgsFunction = completeDetached
+	pauseCmd.ValidArgsFunction = completeDetached
+	resumeCmd.ValidArgsFunction = completeDetached
+}
+
+// completeDetached completes the identifier of a running detached task, as
+// taken by :stop, :logs, :attach, :pause and :resume: the task names and
+// PIDs in the registry
+func completeDetached(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	// Completion skips the pre-run, so --chdir isn't applied yet
+	if dir, _ := cmd.Flags().GetString("chdir"); dir != "" {
+		if err := os.Chdir(dir); err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+	}
+	if err := enterProjectRoot(); err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	// Without a config, the default state dir is still worth a look
+	config, err := loadConfig()
+	if err != nil {
+		config = &runner.Config{}
+	}
+	taskRunner := newRunner(config)
+	taskRunner.Out = io.Discard
+
+	processes, err := taskRunner.ListDetachedProcesses()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	var candidates []string
+	seen := make(map[string]bool)
+	for _, proc := range processes {
+		if !seen[proc.TaskName] {
+			seen[proc.TaskName] = true
+			candidates = append(candidates, fmt.Sprintf("%s\tdetached task", proc.TaskName))
+		}
+	}
+	for _, proc := range processes {
+		description := proc.TaskName
+		if proc.ScheduledAt != nil {
+			description += " (scheduled)"
+		}
+		candidates = append(candidates, fmt.Sprintf("%s\t%s", strconv.Itoa(proc.PID), description))
+	}
+	return candidates, cobra.ShellCompDirectiveNoFileComp
+}
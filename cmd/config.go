@@ -0,0 +1,294 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"t/internal/runner"
+	"t/internal/ui"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// configFile is the config source selected with --file, empty if unset
+	configFile string
+	// allowRemote permits loading the config from an http(s) URL
+	allowRemote bool
+	// envFiles are the --env-file files, layered in order
+	envFiles []string
+	// noGlobal skips the user-global config selected with --no-global
+	noGlobal bool
+	// stateDir is the --state-dir flag, overriding the config's state_dir
+	stateDir string
+	// configProfile is the profile selected with --config-profile
+	configProfile string
+	// runContext bounds the whole invocation when --max-runtime is given
+	runContext context.Context
+	// cancelRun releases runContext
+	cancelRun context.CancelFunc
+	// builtinCommands returns t's built-in commands. It is set in init, as
+	// rootCmd itself loads configs.
+	builtinCommands func() []*cobra.Command
+	// verbose reports extra details such as the config file in use
+	verbose bool
+	// noPrefix leaves the output of parallel dependencies interleaved as is
+	noPrefix bool
+	// groupOutput prints the output of parallel dependencies one after
+	// another instead of interleaved
+	groupOutput bool
+	// interactive allows prompting for input, --interactive=false makes
+	// tasks that would prompt fail instead
+	interactive bool
+	// shellFlags replaces the shell arguments of the config, see --shell-flags
+	shellFlags string
+)
+
+func init() {
+	builtinCommands = rootCmd.Commands
+}
+
+// defaultConfigNames are the config files looked for in the current
+// directory when no source is given, in order of preference
+var defaultConfigNames = []string{"tasks.yaml", "tasks.yml", ".tasks.yaml"}
+
+// configSource returns the config source to use: the --file flag, then the
+// TASKS_FILE environment variable, then the first of defaultConfigNames that
+// exists, falling back to tasks.yaml
+func configSource() string {
+	if configFile != "" {
+		return configFile
+	}
+	if envFile := os.Getenv("TASKS_FILE"); envFile != "" {
+		return envFile
+	}
+	if name := existingDefaultConfig(); name != "" {
+		return name
+	}
+	return defaultConfigNames[0]
+}
+
+// existingDefaultConfig returns the first of defaultConfigNames that exists
+// in the current directory, or "" if there is none
+func existingDefaultConfig() string {
+	for _, name := range defaultConfigNames {
+		if _, err := os.Stat(name); err == nil {
+			return name
+		}
+	}
+	return ""
+}
+
+// findProjectRoot returns the nearest directory from dir upwards holding
+// one of defaultConfigNames, or "" when there is none
+func findProjectRoot(dir string) string {
+	for {
+		for _, name := range defaultConfigNames {
+			if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+				return dir
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// enterProjectRoot changes to the project root found by findProjectRoot
+// when no config source is given and the current directory has no config,
+// so t run from a subdirectory works as if run in the root
+func enterProjectRoot() error {
+	if configFile != "" || os.Getenv("TASKS_FILE") != "" || existingDefaultConfig() != "" {
+		return nil
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	if root := findProjectRoot(dir); root != "" {
+		return os.Chdir(root)
+	}
+	return nil
+}
+
+// globalConfigPath returns the path of the user-global config,
+// $XDG_CONFIG_HOME/t/tasks.yaml or ~/.config/t/tasks.yaml
+func globalConfigPath() string {
+	configDir := os.Getenv("XDG_CONFIG_HOME")
+	if configDir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		configDir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(configDir, "t", "tasks.yaml")
+}
+
+// loadConfig loads the config with loadUncheckedConfig and validates it, so
+// that deps and task references naming no task fail before anything runs
+func loadConfig() (*runner.Config, error) {
+	config, err := loadUncheckedConfig()
+	if err != nil {
+		return nil, err
+	}
+	if err := validateConfig(config); err != nil {
+		return nil, fmt.Errorf("invalid config:\n%w", err)
+	}
+	return config, nil
+}
+
+// loadUncheckedConfig loads the project config and merges it over the
+// user-global config, if there is one and --no-global isn't given, then
+// applies the profile selected with --config-profile
+func loadUncheckedConfig() (*runner.Config, error) {
+	config, err := loadMergedConfig()
+	if err != nil || configProfile == "" {
+		return config, err
+	}
+
+	if err := config.ApplyProfile(configProfile); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// validateConfig runs the config's own checks, see runner.Config.Validate,
+// and reports tasks named like a built-in command or one of its aliases,
+// which run the command instead of the task
+func validateConfig(config *runner.Config) error {
+	errs := []error{config.Validate()}
+	builtins := make(map[string]string)
+	for _, command := range builtinCommands() {
+		for _, name := range append([]string{command.Name()}, command.Aliases...) {
+			builtins[name] = command.Name()
+		}
+	}
+	for _, taskName := range sortedKeys(config.Tasks) {
+		if builtin, taken := builtins[taskName]; taken {
+			errs = append(errs, fmt.Errorf("task %s: the name is taken by the built-in %s command", taskName, builtin))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// loadMergedConfig loads the project config merged over the user-global one
+func loadMergedConfig() (*runner.Config, error) {
+	project, err := loadProjectConfig()
+
+	globalPath := globalConfigPath()
+	if noGlobal || globalPath == "" {
+		return project, err
+	}
+	if _, statErr := os.Stat(globalPath); statErr != nil {
+		return project, err
+	}
+
+	global, globalErr := runner.LoadConfig(globalPath)
+	if globalErr != nil {
+		return nil, globalErr
+	}
+
+	if err != nil {
+		// Outside a project the global tasks are still available
+		_, statErr := os.Stat(configSource())
+		if configFile != "" || os.Getenv("TASKS_FILE") != "" || !os.IsNotExist(statErr) {
+			return nil, err
+		}
+		project = &runner.Config{}
+	}
+
+	return runner.MergeConfigs(global, globalPath, project), nil
+}
+
+// loadProjectConfig loads the configuration from the source given by
+// configSource: a path, "-" for stdin, or an http(s) URL when --allow-remote
+// is given
+func loadProjectConfig() (*runner.Config, error) {
+	source := configSource()
+	switch {
+	case source == "-":
+		config, err := runner.LoadConfigReader(os.Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("stdin: %w", err)
+		}
+		return config, nil
+	case strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://"):
+		if !allowRemote {
+			return nil, fmt.Errorf("refusing to load remote config %s without --allow-remote", source)
+		}
+		fmt.Fprintf(ui.Stdout, "🌐 Using remote config: %s\n", source)
+		return runner.LoadConfigURL(source)
+	default:
+		if verbose {
+			fmt.Fprintf(ui.Stdout, "📄 Using config: %s\n", source)
+		}
+		return runner.LoadConfig(source)
+	}
+}
+
+// newRunner creates a runner whose messages follow the selected output style,
+// whose commands see the profile env and the variables from --env-file and
+// whose state lives in the --state-dir or state_dir directory
+func newRunner(config *runner.Config) *runner.Runner {
+	taskRunner := runner.NewRunner(config)
+	taskRunner.Out = runner.MaskingWriter(ui.Stdout)
+	taskRunner.CommandOut = runner.MaskingWriter(ui.CommandStdout)
+	taskRunner.Context = runContext
+	taskRunner.PrefixDeps = !noPrefix
+	taskRunner.PrefixColors = !ui.Plain()
+	taskRunner.GroupOutput = groupOutput
+	taskRunner.NonInteractive = !interactive
+	if shellFlags != "" {
+		taskRunner.ShellArgs = strings.Fields(shellFlags)
+		if err := runner.CheckShellArgs(taskRunner.ShellArgs); err != nil {
+			fmt.Fprintf(ui.Stdout, "❌ Invalid --shell-flags: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	taskRunner.ConfigSource = configSource()
+	if taskRunner.ConfigSource == "-" {
+		taskRunner.ConfigSource = "stdin"
+	}
+
+	dir := stateDir
+	if dir == "" {
+		dir = config.StateDir
+	}
+	resolved, err := runner.ResolveStateDir(dir)
+	if err != nil {
+		fmt.Fprintf(ui.Stdout, "❌ Error resolving state dir: %v\n", err)
+		os.Exit(1)
+	}
+	taskRunner.StateDir = resolved
+
+	// Profile env comes first so --env-file can still override it
+	if profile, ok := config.Profiles[configProfile]; ok && len(profile.Env) > 0 {
+		taskRunner.Env = make(map[string]string, len(profile.Env))
+		for key, value := range profile.Env {
+			taskRunner.Env[key] = value
+		}
+	}
+
+	for _, envFile := range envFiles {
+		env, err := runner.LoadEnvFile(envFile)
+		if err != nil {
+			fmt.Fprintf(ui.Stdout, "❌ Error loading env file: %v\n", err)
+			os.Exit(1)
+		}
+		if taskRunner.Env == nil {
+			taskRunner.Env = make(map[string]string)
+		}
+		for key, value := range env {
+			taskRunner.Env[key] = value
+		}
+	}
+
+	return taskRunner
+}
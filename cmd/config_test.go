@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testProject creates a project with a tasks.yaml and a nested src/app
+// directory and changes to the nested directory for the length of a test
+func testProject(t *testing.T) (root string, nested string) {
+	t.Helper()
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	nested = filepath.Join(root, "src", "app")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+	config := "tasks:\n  build:\n    cmds: [\"echo build\"]\n"
+	if err := os.WriteFile(filepath.Join(root, "tasks.yaml"), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	previous, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(nested); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(previous) })
+	t.Setenv("TASKS_FILE", "")
+	noGlobal = true
+	t.Cleanup(func() { noGlobal = false })
+	return root, nested
+}
+
+func TestNestedDirRunsInProjectRoot(t *testing.T) {
+	root, _ := testProject(t)
+
+	if err := enterProjectRoot(); err != nil {
+		t.Fatal(err)
+	}
+	config, err := loadConfig()
+	if err != nil {
+		t.Fatalf("config not found from a nested dir: %v", err)
+	}
+	if _, ok := config.Tasks["build"]; !ok {
+		t.Errorf("loaded config lacks the build task")
+	}
+	if dir := newRunner(config).Dir; dir != root {
+		t.Errorf("runner dir is %s, want the project root %s", dir, root)
+	}
+}
+
+func TestNestedConfigWinsOverProjectRoot(t *testing.T) {
+	_, nested := testProject(t)
+	if err := os.WriteFile(filepath.Join(nested, "tasks.yml"), []byte("tasks: {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := enterProjectRoot(); err != nil {
+		t.Fatal(err)
+	}
+	if dir, _ := os.Getwd(); dir != nested {
+		t.Errorf("moved to %s, want to stay in %s", dir, nested)
+	}
+}
+
+func TestLoadConfigValidatesReferences(t *testing.T) {
+	root, _ := testProject(t)
+	config := "tasks:\n  release:\n    deps: [biuld]\n  \":ls\":\n    cmds: [\"ls\"]\n  build:\n    cmds: [\"echo build\"]\n"
+	if err := os.WriteFile(filepath.Join(root, "tasks.yaml"), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := enterProjectRoot(); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := loadConfig()
+	if err == nil {
+		t.Fatal("expected the config to fail validation")
+	}
+	for _, want := range []string{`dep "biuld" is not a task`, "task :ls: the name is taken by the built-in :list command"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q lacks %q", err, want)
+		}
+	}
+}
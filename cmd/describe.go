@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"t/internal/runner"
+	"t/internal/ui"
+
+	"github.com/spf13/cobra"
+)
+
+var describeCmd = &cobra.Command{
+	Use:     ":describe <task-name>",
+	Aliases: []string{":desc", ":show", ":info"},
+	Short:   "Show details of a task",
+	Long:    "Display a task's description, dependencies, effective variables, interactive inputs and commands.",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		taskName := args[0]
+
+		// Load config
+		config, err := loadConfig()
+		if err != nil {
+			fmt.Fprintf(ui.Stdout, "❌ Error loading config: %v\n", err)
+			fmt.Fprintln(ui.Stdout, "\n💡 Tip: Run 't :init' to create a tasks.yaml file")
+			os.Exit(1)
+		}
+
+		task, exists := config.Tasks[taskName]
+		if !exists {
+			fmt.Fprintf(ui.Stdout, "❌ Task %s not found\n", taskName)
+			if suggestions := runner.SuggestTasks(config, taskName); len(suggestions) > 0 {
+				fmt.Fprintf(ui.Stdout, "\n💡 Did you mean: %s?\n", strings.Join(suggestions, ", "))
+			}
+			fmt.Fprintln(ui.Stdout, "\n💡 Use 't :list' to see available tasks")
+			os.Exit(1)
+		}
+
+		taskRunner := newRunner(config)
+		vars, err := taskRunner.EffectiveVars(taskName)
+		if err != nil {
+			fmt.Fprintf(ui.Stdout, "❌ Error expanding vars: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Fprintf(ui.Stdout, "🔧 Task: %s\n", taskName)
+		if task.Desc != "" {
+			fmt.Fprintf(ui.Stdout, "📖 Description: %s\n", task.Desc)
+		}
+		if task.Group != "" {
+			fmt.Fprintf(ui.Stdout, "📁 Group: %s\n", task.Group)
+		}
+		if len(task.Labels) > 0 {
+			fmt.Fprintf(ui.Stdout, "🏷️  Labels: %v\n", task.Labels)
+		}
+		if len(task.Requires) > 0 {
+			fmt.Fprintf(ui.Stdout, "🧰 Requires: %v\n", task.Requires)
+		}
+		if task.LongRunning {
+			fmt.Fprintln(ui.Stdout, "🚀 Long-running, start it with 't :detach'")
+		}
+		if runner.IsInternalTask(taskName, task) {
+			fmt.Fprintln(ui.Stdout, "🙈 Internal task")
+		}
+		if len(task.Deps) > 0 {
+			fmt.Fprintf(ui.Stdout, "🔗 Depends on: %v\n", task.Deps)
+			if task.DepsAlways {
+				fmt.Fprintln(ui.Stdout, "🔗 Deps run again for this task even if they already ran")
+			}
+		}
+		if task.When != "" {
+			fmt.Fprintf(ui.Stdout, "⏭️  Only runs when: %s\n", task.When)
+		}
+
+		if len(vars) > 0 {
+			fmt.Fprintln(ui.Stdout, "\n📦 Variables:")
+			for _, name := range sortedKeys(vars) {
+				source := "global"
+				profile := config.Profiles[configProfile]
+				if runner.IsBuiltinVar(name) {
+					source = "built-in"
+				} else if _, ok := task.Vars[name]; ok {
+					source = "task"
+				} else if _, ok := profile.Vars[name]; ok {
+					source = "profile " + configProfile
+				} else if _, ok := profile.Env[name]; ok {
+					source = "profile " + configProfile
+				}
+				fmt.Fprintf(ui.Stdout, "   %s = %s (%s)\n", name, runner.MaskSecrets(vars[name]), source)
+			}
+		}
+
+		printInteractiveInputs(task)
+		printCommands("Before", task.Before)
+		printCommands("Commands", task.Cmds)
+		printCommands("After", task.After)
+	},
+}
+
+// printInteractiveInputs prints the inputs a task prompts for with their
+// message, default and whether they are required
+func printInteractiveInputs(task runner.Task) {
+	if len(task.Interactive) == 0 {
+		return
+	}
+
+	fmt.Fprintln(ui.Stdout, "\n🤔 Interactive inputs:")
+	for _, name := range task.InteractiveNames() {
+		prompt := task.Interactive[name]
+		fmt.Fprintf(ui.Stdout, "   $%s - %s", name, prompt.Message)
+		if prompt.Default != "" {
+			fmt.Fprintf(ui.Stdout, " [%s]", prompt.Default)
+		}
+		if prompt.Required {
+			fmt.Fprintf(ui.Stdout, " (required)")
+		}
+		fmt.Fprintln(ui.Stdout)
+	}
+}
+
+// printCommands prints a titled command list, skipping empty lists
+func printCommands(title string, commands []runner.Command) {
+	if len(commands) == 0 {
+		return
+	}
+
+	fmt.Fprintf(ui.Stdout, "\n➡️  %s:\n", title)
+	for _, command := range commands {
+		if command.Task != "" {
+			fmt.Fprintf(ui.Stdout, "   task: %s\n", command.Task)
+		} else {
+			fmt.Fprintf(ui.Stdout, "   %s\n", command.Line())
+		}
+	}
+}
+
+// sortedKeys returns the keys of a map in alphabetical order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
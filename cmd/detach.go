@@ -1,9 +1,12 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"os"
 
 	"t/internal/runner"
+	"t/internal/ui"
 
 	"github.com/spf13/cobra"
 )
@@ -18,23 +21,59 @@ var detachCmd = &cobra.Command{
 		taskName := args[0]
 
 		// Load config
-		config, err := runner.LoadConfig("tasks.yaml")
+		config, err := loadConfig()
 		if err != nil {
-			fmt.Printf("❌ Error loading config: %v\n", err)
-			fmt.Println("\n💡 Tip: Run 't :init' to create a tasks.yaml file")
-			return
+			fmt.Fprintf(ui.Stdout, "❌ Error loading config: %v\n", err)
+			fmt.Fprintln(ui.Stdout, "\n💡 Tip: Run 't :init' to create a tasks.yaml file")
+			os.Exit(1)
 		}
 
-		taskRunner := runner.NewRunner(config)
+		if task, exists := config.Tasks[taskName]; exists && !task.LongRunning {
+			fmt.Fprintf(ui.Stdout, "⚠️  Task '%s' is not marked long_running and may exit right away\n", taskName)
+			fmt.Fprintln(ui.Stdout, "💡 Add 'long_running: true' to tasks meant to run in the background")
+		}
+
+		taskRunner := newRunner(config)
+		taskRunner.AssumeYes, _ = cmd.Flags().GetBool("yes")
+		taskRunner.DetachedStdin, _ = cmd.Flags().GetString("stdin")
+		taskRunner.AllowDuplicate, _ = cmd.Flags().GetBool("allow-duplicate")
+		taskRunner.DetachedDryRun, _ = cmd.Flags().GetBool("dry-run")
+		taskRunner.WaitForLock, _ = cmd.Flags().GetBool("wait")
+
+		// The log goes through t's log writer so it can be rotated. Without
+		// it the task writes the file itself, which can't be timestamped.
+		self, err := os.Executable()
+		timestamps, _ := cmd.Flags().GetBool("timestamps")
+		switch {
+		case err == nil && timestamps:
+			taskRunner.DetachedLogWriter = []string{self, ":log-writer", "--timestamps"}
+		case err == nil:
+			taskRunner.DetachedLogWriter = []string{self, ":log-writer"}
+		case timestamps:
+			fmt.Fprintf(ui.Stdout, "❌ Cannot timestamp logs: %v\n", err)
+			os.Exit(1)
+		}
 
 		// Run task in detached mode
 		detachedProc, err := taskRunner.RunTaskDetached(taskName)
 		if err != nil {
-			fmt.Printf("❌ Failed to start detached task: %v\n", err)
-			return
+			fmt.Fprintf(ui.Stdout, "❌ Failed to start detached task: %v\n", err)
+			if errors.Is(err, runner.ErrAlreadyRunning) {
+				fmt.Fprintf(ui.Stdout, "\n💡 See it with 't :ps' or 't :logs %s', stop it with 't :stop %s'\n", taskName, taskName)
+				fmt.Fprintln(ui.Stdout, "💡 Use --allow-duplicate to start another instance anyway")
+			}
+			os.Exit(1)
 		}
 
 		// Show success message (already printed in RunTaskDetached)
 		_ = detachedProc
 	},
 }
+
+func init() {
+	detachCmd.Flags().String("stdin", "", "File to feed to the task's stdin (default: the null device)")
+	detachCmd.Flags().Bool("allow-duplicate", false, "Start the task even if it is already running in the background")
+	detachCmd.Flags().Bool("wait", false, "Wait for the task's lock when another run holds it, instead of failing")
+	detachCmd.Flags().Bool("dry-run", false, "Show the commands, log file and process setup without starting anything")
+	detachCmd.Flags().Bool("timestamps", false, "Prefix each log line with an RFC3339 timestamp (enables ':logs --since <time>')")
+}
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"t/internal/runner"
+	"t/internal/ui"
+
+	"github.com/spf13/cobra"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   ":doctor",
+	Short: "Diagnose the environment",
+	Long:  "Check that the config file parses, the shell is available, the log and process directories are writable, and list stale detached process entries.",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Fprintln(ui.Stdout, "🩺 Checking environment:")
+		fmt.Fprintln(ui.Stdout)
+
+		healthy := true
+		check := func(ok bool, critical bool, message string, detail error) {
+			switch {
+			case ok:
+				fmt.Fprintf(ui.Stdout, "  ✅ %s\n", message)
+			case critical:
+				healthy = false
+				fmt.Fprintf(ui.Stdout, "  ❌ %s: %s\n", message, indentDetail(detail))
+			default:
+				fmt.Fprintf(ui.Stdout, "  ⚠️  %s: %s\n", message, indentDetail(detail))
+			}
+		}
+
+		source := configSource()
+		config, err := loadUncheckedConfig()
+		check(err == nil, true, fmt.Sprintf("Config %s loads", source), err)
+		if config != nil {
+			fmt.Fprintf(ui.Stdout, "     %d tasks defined\n", len(config.Tasks))
+			err := validateConfig(config)
+			check(err == nil, true, "Deps, task references and task names are valid", err)
+			empty := config.EmptyTasks()
+			if len(empty) > 0 {
+				err = fmt.Errorf("no cmds, deps or hooks in %s", strings.Join(empty, ", "))
+			}
+			check(len(empty) == 0, false, "Every task has cmds or deps", err)
+		}
+
+		if config != nil {
+			for _, taskName := range sortedKeys(config.Tasks) {
+				task := config.Tasks[taskName]
+				if len(task.Requires) == 0 {
+					continue
+				}
+				var err error
+				if missing := runner.MissingRequirements(task); len(missing) > 0 {
+					err = fmt.Errorf("missing %s", strings.Join(missing, ", "))
+				}
+				check(err == nil, false, fmt.Sprintf("Tools required by %s are on PATH", taskName), err)
+			}
+		}
+
+		shell := "sh"
+		if runtime.GOOS == "windows" {
+			shell = "powershell"
+		}
+		_, err = exec.LookPath(shell)
+		check(err == nil, true, fmt.Sprintf("Shell %s is on PATH", shell), err)
+
+		if runtime.GOOS != "windows" {
+			_, err = exec.LookPath("ps")
+			check(err == nil, false, "ps is on PATH (used to track detached tasks)", err)
+		}
+
+		if config == nil {
+			config = &runner.Config{}
+		}
+		taskRunner := newRunner(config)
+
+		for _, dir := range []string{taskRunner.LogsDir(), taskRunner.ProcessesDir()} {
+			err := checkWritable(dir)
+			check(err == nil, false, fmt.Sprintf("%s is writable", dir), err)
+		}
+
+		stale, err := taskRunner.StaleDetachedProcesses()
+		if err == nil && len(stale) > 0 {
+			err = fmt.Errorf("%d found, run 't :ps' to clean them up", len(stale))
+		}
+		check(err == nil, false, "Detached process registry has no stale entries", err)
+		for _, proc := range stale {
+			fmt.Fprintf(ui.Stdout, "     • %s (PID %d)\n", proc.TaskName, proc.PID)
+		}
+
+		fmt.Fprintln(ui.Stdout)
+		if !healthy {
+			fmt.Fprintln(ui.Stdout, "❌ Some critical checks failed")
+			os.Exit(1)
+		}
+		fmt.Fprintln(ui.Stdout, "🎉 Everything looks good!")
+	},
+}
+
+// indentDetail formats a check's error, indenting the lines of errors that
+// span several lines under the check
+func indentDetail(detail error) string {
+	return strings.ReplaceAll(fmt.Sprint(detail), "\n", "\n     ")
+}
+
+// checkWritable reports whether files can be created in dir. A missing dir
+// is fine as long as it can be created in its closest existing parent.
+func checkWritable(dir string) error {
+	for {
+		if _, err := os.Stat(dir); !os.IsNotExist(err) {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	file, err := os.CreateTemp(dir, ".t-doctor-*")
+	if err != nil {
+		return err
+	}
+	file.Close()
+	return os.Remove(file.Name())
+}
@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"t/internal/runner"
+	"t/internal/ui"
+
+	"github.com/spf13/cobra"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   ":export <format>",
+	Short: "Convert tasks.yaml to another format",
+	Long:  "Convert the task file to another format. Supported formats: makefile.",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+		overwrite, _ := cmd.Flags().GetBool("overwrite")
+
+		if args[0] != "makefile" {
+			fmt.Fprintf(ui.Stdout, "❌ Unsupported export format: %s (supported: makefile)\n", args[0])
+			os.Exit(1)
+		}
+
+		// Load config, leaving out personal tasks from the global config
+		noGlobal = true
+		config, err := loadConfig()
+		if err != nil {
+			fmt.Fprintf(ui.Stdout, "❌ Error loading config: %v\n", err)
+			fmt.Fprintln(ui.Stdout, "\n💡 Tip: Run 't :init' to create a tasks.yaml file")
+			os.Exit(1)
+		}
+
+		content := toMakefile(config)
+
+		if output == "-" {
+			fmt.Print(content)
+			return
+		}
+
+		if _, err := os.Stat(output); err == nil && !overwrite {
+			fmt.Fprintf(ui.Stdout, "❌ %s already exists\n", output)
+			fmt.Fprintln(ui.Stdout, "Remove it first or pass --overwrite")
+			os.Exit(1)
+		}
+
+		if err := os.WriteFile(output, []byte(content), 0644); err != nil {
+			fmt.Fprintf(ui.Stdout, "❌ Error writing %s: %v\n", output, err)
+			os.Exit(1)
+		}
+
+		fmt.Fprintf(ui.Stdout, "✅ Exported %d tasks to %s\n", len(config.Tasks), output)
+	},
+}
+
+func init() {
+	exportCmd.Flags().StringP("output", "o", "Makefile", "File to write, '-' for stdout")
+	exportCmd.Flags().Bool("overwrite", false, "Replace the output file if it already exists")
+}
+
+// simpleVarPattern matches plain variable references like {{.NAME}}
+var simpleVarPattern = regexp.MustCompile(`{{\s*\.(\w+)\s*}}`)
+
+// makeValue converts a tasks.yaml string into Make syntax. Dollar signs are
+// escaped and plain variable references become $(NAME).
+func makeValue(value string) string {
+	value = strings.ReplaceAll(value, "$", "$$")
+	return simpleVarPattern.ReplaceAllString(value, "$$($1)")
+}
+
+// toMakefile renders the config as a Makefile. Features without a Make
+// equivalent are kept as warning comments.
+func toMakefile(config *runner.Config) string {
+	var b strings.Builder
+
+	b.WriteString("# Generated by 't :export makefile' from tasks.yaml\n")
+	b.WriteString("# Detached runs (t :detach) have no Make equivalent.\n\n")
+
+	for _, name := range sortedKeys(config.Vars) {
+		fmt.Fprintf(&b, "%s := %s\n", name, makeValue(config.Vars[name]))
+	}
+	if len(config.Vars) > 0 {
+		b.WriteString("\n")
+	}
+
+	names := sortedKeys(config.Tasks)
+	fmt.Fprintf(&b, ".PHONY: %s\n", strings.Join(names, " "))
+
+	for _, name := range names {
+		task := config.Tasks[name]
+		b.WriteString("\n")
+
+		if task.Desc != "" {
+			fmt.Fprintf(&b, "# %s\n", task.Desc)
+		}
+		for _, warning := range makefileWarnings(task) {
+			fmt.Fprintf(&b, "# WARNING: %s\n", warning)
+		}
+
+		for _, varName := range sortedKeys(task.Vars) {
+			fmt.Fprintf(&b, "%s: %s := %s\n", name, varName, makeValue(task.Vars[varName]))
+		}
+
+		deps := make([]string, len(task.Deps.Names()))
+		for i, dep := range task.Deps.Names() {
+			deps[i] = makeValue(dep)
+		}
+		fmt.Fprintf(&b, "%s:", name)
+		if len(deps) > 0 {
+			fmt.Fprintf(&b, " %s", strings.Join(deps, " "))
+		}
+		b.WriteString("\n")
+
+		for _, command := range task.Cmds {
+			if command.Task != "" {
+				fmt.Fprintf(&b, "\t$(MAKE) %s\n", makeValue(command.Task))
+				continue
+			}
+
+			line := makeValue(command.Line())
+			if command.Dir != "" {
+				// Each recipe line has its own shell, so the cd stays local
+				line = "cd " + makeValue(command.Dir) + " && " + line
+			}
+			if !command.ShouldEcho() {
+				line = "@" + line
+			}
+			fmt.Fprintf(&b, "\t%s\n", line)
+		}
+	}
+
+	return b.String()
+}
+
+// makefileWarnings lists the task features that are lost in a Makefile
+func makefileWarnings(task runner.Task) []string {
+	var warnings []string
+	if len(task.Interactive) > 0 {
+		warnings = append(warnings, "interactive inputs are not supported, pass them as make variables")
+	}
+	if len(task.Matrix) > 0 {
+		warnings = append(warnings, "matrix is not supported, commands run once")
+	}
+	if len(task.Before) > 0 || len(task.After) > 0 {
+		warnings = append(warnings, "before/after hooks are not supported")
+	}
+	if len(task.Deps) > 1 {
+		warnings = append(warnings, "dependency stages are not enforced, make -j may run them together")
+	}
+	for _, stage := range task.Deps {
+		for _, dep := range stage {
+			if dep.AllowFailure {
+				warnings = append(warnings, "allow_failure is not supported, a failing "+dep.Task+" stops make")
+			}
+			if dep.Always || task.DepsAlways {
+				warnings = append(warnings, "always is not supported, make builds "+dep.Task+" once")
+			}
+		}
+	}
+	if task.Parallel {
+		warnings = append(warnings, "parallel commands are not supported, they run one after another")
+	}
+	if task.Confirm.Enabled {
+		warnings = append(warnings, "confirmation prompt is not supported")
+	}
+	if task.Lock.Enabled {
+		warnings = append(warnings, "lock is not supported, separate make runs can overlap")
+	}
+	for _, command := range task.Cmds {
+		if command.Prefix != "" {
+			warnings = append(warnings, "output prefixes are not supported")
+			break
+		}
+	}
+	for _, command := range task.Cmds {
+		if command.Capture != "" {
+			warnings = append(warnings, "capture is not supported, captured $variables stay unset")
+			break
+		}
+	}
+	for _, command := range task.Cmds {
+		if command.Stdin != "" || command.StdinFile != "" {
+			warnings = append(warnings, "stdin and stdin_file are not supported")
+			break
+		}
+	}
+	for _, command := range task.Cmds {
+		if strings.Contains(simpleVarPattern.ReplaceAllString(command.Line(), ""), "{{") {
+			warnings = append(warnings, "template expressions other than {{.VAR}} were copied verbatim")
+			break
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"t/internal/runner"
+	"t/internal/ui"
+
+	"github.com/spf13/cobra"
+)
+
+var findCmd = &cobra.Command{
+	Use:     ":find <query>",
+	Aliases: []string{":search", ":f"},
+	Short:   "Search tasks by name and description",
+	Long:    "Find tasks whose name or description contains the query (case-insensitive). With --deps, also match tasks whose dependency chain includes a matching task.",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		query := strings.ToLower(args[0])
+		matchDeps, _ := cmd.Flags().GetBool("deps")
+
+		// Load config
+		config, err := loadConfig()
+		if err != nil {
+			fmt.Fprintf(ui.Stdout, "❌ Error loading config: %v\n", err)
+			fmt.Fprintln(ui.Stdout, "\n💡 Tip: Run 't :init' to create a tasks.yaml file")
+			return
+		}
+
+		var names []string
+		for taskName, task := range config.Tasks {
+			if taskMatches(taskName, task, query) ||
+				(matchDeps && depChainMatches(config, taskName, query, map[string]bool{})) {
+				names = append(names, taskName)
+			}
+		}
+		sort.Strings(names)
+
+		if len(names) == 0 {
+			fmt.Fprintf(ui.Stdout, "📭 No tasks match '%s'\n", args[0])
+			return
+		}
+
+		fmt.Fprintf(ui.Stdout, "📋 Tasks matching '%s':\n", args[0])
+		fmt.Fprintln(ui.Stdout)
+		for _, taskName := range names {
+			printTask(taskName, config.Tasks[taskName])
+		}
+		fmt.Fprintln(ui.Stdout)
+	},
+}
+
+func init() {
+	findCmd.Flags().Bool("deps", false, "Also match tasks whose dependency chain includes a matching task")
+}
+
+// taskMatches reports whether the task name or description contains the query
+func taskMatches(taskName string, task runner.Task, query string) bool {
+	return strings.Contains(strings.ToLower(taskName), query) ||
+		strings.Contains(strings.ToLower(task.Desc), query)
+}
+
+// depChainMatches reports whether any direct or indirect dependency of the
+// task matches the query
+func depChainMatches(config *runner.Config, taskName string, query string, visited map[string]bool) bool {
+	if visited[taskName] {
+		return false
+	}
+	visited[taskName] = true
+
+	for _, dep := range config.Tasks[taskName].Deps.Names() {
+		if taskMatches(dep, config.Tasks[dep], query) || depChainMatches(config, dep, query, visited) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"t/internal/runner"
+	"t/internal/ui"
+
+	"github.com/spf13/cobra"
+)
+
+var historyCmd = &cobra.Command{
+	Use:     ":history",
+	Aliases: []string{":hist"},
+	Short:   "Show recent task runs",
+	Long:    "Show the most recent task runs with their start time, duration and exit status. Runs are only recorded when tasks.yaml sets 'history: true'.",
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		limit, _ := cmd.Flags().GetInt("limit")
+		taskName, _ := cmd.Flags().GetString("task")
+		asJSON, _ := cmd.Flags().GetBool("json")
+
+		config, err := loadConfig()
+		if err != nil {
+			config = &runner.Config{} // Empty config
+		}
+
+		entries, err := newRunner(config).ReadHistory()
+		if err != nil {
+			fmt.Fprintf(ui.Stdout, "❌ Error reading history: %v\n", err)
+			os.Exit(1)
+		}
+
+		if taskName != "" {
+			var matching []runner.HistoryEntry
+			for _, entry := range entries {
+				if entry.Task == taskName {
+					matching = append(matching, entry)
+				}
+			}
+			entries = matching
+		}
+		if limit > 0 && len(entries) > limit {
+			entries = entries[len(entries)-limit:]
+		}
+
+		if asJSON {
+			if entries == nil {
+				entries = []runner.HistoryEntry{}
+			}
+			data, _ := json.MarshalIndent(entries, "", "  ")
+			fmt.Fprintln(ui.Stdout, string(data))
+			return
+		}
+
+		if len(entries) == 0 {
+			fmt.Fprintln(ui.Stdout, "📭 No runs recorded")
+			fmt.Fprintln(ui.Stdout, "\n💡 Add 'history: true' to tasks.yaml to record runs")
+			return
+		}
+
+		fmt.Fprintf(ui.Stdout, "📜 Last %d run(s):\n\n", len(entries))
+		w := tabwriter.NewWriter(ui.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "  STARTED\tTASK\tDURATION\tSTATUS")
+		for _, entry := range entries {
+			status := "ok"
+			if !entry.Succeeded() {
+				status = fmt.Sprintf("failed (exit %d)", entry.ExitCode)
+			}
+			fmt.Fprintf(w, "  %s\t%s\t%v\t%s\n",
+				entry.StartedAt.Local().Format("2006-01-02 15:04:05"),
+				entry.Task,
+				entry.Duration.Round(time.Millisecond),
+				status)
+		}
+		w.Flush()
+	},
+}
+
+func init() {
+	historyCmd.Flags().IntP("limit", "n", 20, "Show at most this many runs, 0 for all")
+	historyCmd.Flags().String("task", "", "Only show runs of this task")
+	historyCmd.Flags().Bool("json", false, "Print the runs as JSON")
+}
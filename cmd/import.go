@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"t/internal/ui"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var importCmd = &cobra.Command{
+	Use:   ":import <package.json>",
+	Short: "Import npm scripts into tasks.yaml",
+	Long:  "Convert the \"scripts\" of a package.json into tasks. Existing tasks are kept; scripts whose name is already a task are skipped.",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		source := args[0]
+		if filepath.Base(source) != "package.json" {
+			fmt.Fprintf(ui.Stdout, "❌ Unsupported import source: %s (supported: package.json)\n", source)
+			os.Exit(1)
+		}
+
+		target := configSource()
+		if target == "-" || strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://") {
+			fmt.Fprintf(ui.Stdout, "❌ Cannot import into %s, use a local file with --file\n", target)
+			os.Exit(1)
+		}
+
+		scripts, err := readNpmScripts(source)
+		if err != nil {
+			fmt.Fprintf(ui.Stdout, "❌ Error reading %s: %v\n", source, err)
+			os.Exit(1)
+		}
+		if len(scripts) == 0 {
+			fmt.Fprintf(ui.Stdout, "📭 No scripts found in %s\n", source)
+			return
+		}
+
+		added, skipped, err := mergeScripts(target, scripts)
+		if err != nil {
+			fmt.Fprintf(ui.Stdout, "❌ Error updating %s: %v\n", target, err)
+			os.Exit(1)
+		}
+
+		for _, name := range skipped {
+			fmt.Fprintf(ui.Stdout, "⚠️  Skipped %s: a task with that name already exists\n", name)
+		}
+		fmt.Fprintf(ui.Stdout, "✅ Imported %d scripts into %s\n", len(added), target)
+	},
+}
+
+// readNpmScripts returns the "scripts" object of a package.json
+func readNpmScripts(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var pkg struct {
+		Scripts map[string]string `json:"scripts"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil, err
+	}
+	return pkg.Scripts, nil
+}
+
+// mergeScripts adds a task for every script to the config file, creating it
+// if needed. The existing document is edited in place so its tasks, order and
+// comments are kept.
+func mergeScripts(path string, scripts map[string]string) (added, skipped []string, err error) {
+	var doc yaml.Node
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		if err := decoder.Decode(&doc); err != nil && err != io.EOF {
+			return nil, nil, err
+		}
+		// Only the first document would be written back
+		var next yaml.Node
+		if decoder.Decode(&next) != io.EOF {
+			return nil, nil, fmt.Errorf("%s has several YAML documents, add the tasks by hand", path)
+		}
+	case os.IsNotExist(err):
+	default:
+		return nil, nil, err
+	}
+
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, nil, fmt.Errorf("expected a mapping at the top level")
+	}
+
+	tasks := yamlMappingValue(root, "tasks")
+	if tasks != nil && tasks.Tag == "!!null" {
+		*tasks = yaml.Node{Kind: yaml.MappingNode}
+	}
+	if tasks != nil && tasks.Kind != yaml.MappingNode {
+		return nil, nil, fmt.Errorf("the tasks of %s aren't written in it (an !include?), add the tasks by hand", path)
+	}
+	if tasks == nil {
+		tasks = &yaml.Node{Kind: yaml.MappingNode}
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: "tasks"}, tasks)
+	}
+
+	for _, name := range sortedKeys(scripts) {
+		if yamlMappingValue(tasks, name) != nil {
+			skipped = append(skipped, name)
+			continue
+		}
+
+		var task yaml.Node
+		if err := task.Encode(struct {
+			Desc string   `yaml:"desc"`
+			Cmds []string `yaml:"cmds"`
+		}{
+			Desc: fmt.Sprintf("npm script %s", name),
+			Cmds: []string{scripts[name]},
+		}); err != nil {
+			return nil, nil, err
+		}
+		tasks.Content = append(tasks.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: name}, &task)
+		added = append(added, name)
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return nil, nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, nil, err
+	}
+
+	return added, skipped, os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+// yamlMappingValue returns the value node for key in a mapping node, or nil
+func yamlMappingValue(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
@@ -1,94 +1,142 @@
 package cmd
 
 import (
+	"bufio"
+	"bytes"
+	"embed"
 	"fmt"
 	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"t/internal/runner"
+	"t/internal/ui"
 
 	"github.com/spf13/cobra"
 )
 
+// templates holds the starter configs written by :init, one per file
+//
+//go:embed templates/*.yaml
+var templates embed.FS
+
 var initCmd = &cobra.Command{
 	Use:   ":init",
 	Short: "init t file (tasks.yaml)",
-	Long:  "Initialize the task file (tasks.yaml) with a default structure.",
+	Long:  "Initialize the task file (tasks.yaml) from a starter template: " + strings.Join(templateNames(), ", ") + ".",
 	Run: func(cmd *cobra.Command, args []string) {
-		initTasksFile()
+		template, _ := cmd.Flags().GetString("template")
+		force, _ := cmd.Flags().GetBool("force")
+		toStdout, _ := cmd.Flags().GetBool("stdout")
+		assumeYes, _ := cmd.Flags().GetBool("yes")
+		initTasksFile(template, force, toStdout, assumeYes)
 	},
 }
 
-func initTasksFile() {
-	if _, err := os.Stat("tasks.yaml"); err == nil {
-		fmt.Println("❌ tasks.yaml already exists in current directory")
-		fmt.Println("Remove it first or use a different directory")
-		return
+func init() {
+	initCmd.Flags().String("template", "go", "Starter template: "+strings.Join(templateNames(), ", "))
+	initCmd.Flags().Bool("force", false, "Overwrite an existing config file (asks first unless --yes)")
+	initCmd.Flags().Bool("stdout", false, "Print the template instead of writing a file")
+}
+
+// templateNames returns the names of the embedded templates in alphabetical
+// order
+func templateNames() []string {
+	entries, _ := templates.ReadDir("templates")
+	var names []string
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), ".yaml"))
 	}
+	sort.Strings(names)
+	return names
+}
 
-	// Create the tasks.yaml file with a default structure
-	file, err := os.Create("tasks.yaml")
+// initTasksFile writes the template to tasks.yaml, or with force over the
+// existing config file, or prints it with toStdout. It exits non-zero when
+// nothing was written.
+func initTasksFile(template string, force bool, toStdout bool, assumeYes bool) {
+	content, err := templates.ReadFile(path.Join("templates", template+".yaml"))
 	if err != nil {
-		fmt.Printf("❌ Error creating tasks.yaml: %v\n", err)
+		fmt.Fprintf(ui.Stdout, "❌ Unknown template '%s'\n", template)
+		fmt.Fprintf(ui.Stdout, "💡 Available templates: %s\n", strings.Join(templateNames(), ", "))
+		os.Exit(1)
+	}
+
+	// The template goes out unchanged, so it can be piped into a file
+	if toStdout {
+		os.Stdout.Write(content)
 		return
 	}
-	defer file.Close()
-
-	// Default tasks.yaml content
-	defaultContent := `version: "1"
-
-vars:
-  APP_NAME: "myapp"
-  BUILD_DIR: "bin"
-
-tasks:
-  build:
-    desc: "Build the application"
-    deps: [clean]
-    cmds:
-      - "mkdir -p {{.BUILD_DIR}}"
-      - "go build -ldflags='-s -w' -o {{.BUILD_DIR}}/{{.APP_NAME}} ."
-
-  test:
-    desc: "Run tests"
-    cmds:
-      - "go test ./..."
-
-  clean:
-    desc: "Clean build artifacts"
-    cmds:
-      - "rm -rf {{.BUILD_DIR}}"
-      - "rm -f {{.APP_NAME}} {{.APP_NAME}}.exe"
-
-  dev:
-    desc: "Run in development mode"
-    cmds:
-      - "go run ."
-
-  install:
-    desc: "Install dependencies"
-    cmds:
-      - "go mod download"
-      - "go mod tidy"
-
-  lint:
-    desc: "Run linter"
-    cmds:
-      - "go fmt ./..."
-      - "go vet ./..."
-`
-
-	// Write the content to the file
-	_, err = file.WriteString(defaultContent)
+
+	target := "tasks.yaml"
+	if name := existingDefaultConfig(); name != "" {
+		if !force {
+			fmt.Fprintf(ui.Stdout, "❌ %s already exists in current directory\n", name)
+			fmt.Fprintln(ui.Stdout, "Remove it first, use a different directory or pass --force to overwrite it")
+			os.Exit(1)
+		}
+		if !assumeYes && !confirmOverwrite(name) {
+			os.Exit(1)
+		}
+		target = name
+	}
+
+	// Create the config file from the template
+	if err := os.WriteFile(target, content, 0644); err != nil {
+		fmt.Fprintf(ui.Stdout, "❌ Error creating %s: %v\n", target, err)
+		os.Exit(1)
+	}
+
+	config, err := runner.LoadConfigReader(bytes.NewReader(content))
 	if err != nil {
-		fmt.Printf("❌ Error writing to tasks.yaml: %v\n", err)
+		fmt.Fprintf(ui.Stdout, "✅ Created %s from the %s template\n", target, template)
 		return
 	}
 
-	fmt.Println("✅ Created tasks.yaml with default tasks:")
-	fmt.Println("   • build  - Build the application")
-	fmt.Println("   • test   - Run tests")
-	fmt.Println("   • clean  - Clean build artifacts")
-	fmt.Println("   • dev    - Run in development mode")
-	fmt.Println("   • install- Install dependencies")
-	fmt.Println("   • lint   - Run linter")
-	fmt.Println("")
-	fmt.Println("Run 't build' to get started!")
+	// List the tasks in the order the template defines them
+	names := make([]string, 0, len(config.Tasks))
+	width := 0
+	for name := range config.Tasks {
+		names = append(names, name)
+		width = max(width, len(name))
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return config.Tasks[names[i]].Line < config.Tasks[names[j]].Line
+	})
+
+	fmt.Fprintf(ui.Stdout, "✅ Created %s with default %s tasks:\n", target, template)
+	for _, name := range names {
+		fmt.Fprintf(ui.Stdout, "   • %-*s - %s\n", width, name, config.Tasks[name].Desc)
+	}
+	fmt.Fprintln(ui.Stdout, "")
+
+	first := names[0]
+	if _, ok := config.Tasks["build"]; ok {
+		first = "build"
+	}
+	fmt.Fprintf(ui.Stdout, "Run 't %s' to get started!\n", first)
+}
+
+// confirmOverwrite asks whether the existing config file may be replaced.
+// Without a terminal to answer it refuses, since --yes is the way to
+// overwrite from scripts.
+func confirmOverwrite(name string) bool {
+	if stat, err := os.Stdin.Stat(); err != nil || stat.Mode()&os.ModeCharDevice == 0 {
+		fmt.Fprintf(ui.Stdout, "❌ %s already exists, pass --yes to overwrite it non-interactively\n", name)
+		return false
+	}
+
+	fmt.Fprintf(ui.Stdout, "⚠️  Overwrite %s? [y/N]: ", name)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		fmt.Fprintln(ui.Stdout)
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	default:
+		fmt.Fprintf(ui.Stdout, "🛑 Kept the existing %s\n", name)
+		return false
+	}
 }
@@ -2,8 +2,10 @@ package cmd
 
 import (
 	"fmt"
+	"sort"
 
 	"t/internal/runner"
+	"t/internal/ui"
 
 	"github.com/spf13/cobra"
 )
@@ -11,44 +13,125 @@ import (
 var listCmd = &cobra.Command{
 	Use:     ":list",
 	Short:   "List all available tasks",
-	Long:    "Display all tasks defined in the tasks.yaml file with their descriptions.",
+	Long:    "Display all tasks defined in the tasks.yaml file with their descriptions. Internal tasks (marked 'internal: true' or starting with '_') are hidden unless --all is given.",
 	Aliases: []string{":ls", ":tasks"},
 	Run: func(cmd *cobra.Command, args []string) {
-		listTasks()
+		showAll, _ := cmd.Flags().GetBool("all")
+		grouped, _ := cmd.Flags().GetBool("group")
+		label, _ := cmd.Flags().GetString("label")
+		listTasks(showAll, grouped, label)
 	},
 }
 
-func listTasks() {
+func init() {
+	listCmd.Flags().BoolP("all", "a", false, "Include internal tasks")
+	listCmd.Flags().BoolP("group", "g", false, "Organize tasks under their group headings")
+	listCmd.Flags().String("label", "", "Only show tasks carrying this label")
+}
+
+func listTasks(showAll bool, grouped bool, label string) {
 	// Load config
-	config, err := runner.LoadConfig("tasks.yaml")
+	config, err := loadConfig()
 	if err != nil {
-		fmt.Printf("❌ Error loading config: %v\n", err)
-		fmt.Println("\n💡 Tip: Run 't :init' to create a tasks.yaml file")
+		fmt.Fprintf(ui.Stdout, "❌ Error loading config: %v\n", err)
+		fmt.Fprintln(ui.Stdout, "\n💡 Tip: Run 't :init' to create a tasks.yaml file")
 		return
 	}
 
 	if len(config.Tasks) == 0 {
-		fmt.Println("No tasks found in tasks.yaml")
+		fmt.Fprintln(ui.Stdout, "No tasks found in tasks.yaml")
 		return
 	}
 
-	fmt.Println("📋 Available tasks:")
-	fmt.Println()
-
+	// Collect the tasks to show
+	var names []string
+	hidden := 0
 	for taskName, task := range config.Tasks {
-		fmt.Printf("  🔧 %s", taskName)
+		if label != "" && !task.HasLabel(label) {
+			continue
+		}
+		if !showAll && runner.IsInternalTask(taskName, task) {
+			hidden++
+			continue
+		}
+		names = append(names, taskName)
+	}
+	sort.Strings(names)
 
-		if task.Desc != "" {
-			fmt.Printf(" - %s", task.Desc)
+	if len(names) == 0 && label != "" {
+		fmt.Fprintf(ui.Stdout, "No tasks found with label '%s'\n", label)
+		return
+	}
+
+	fmt.Fprintln(ui.Stdout, "📋 Available tasks:")
+	fmt.Fprintln(ui.Stdout)
+
+	if grouped {
+		// Organize tasks by group, ungrouped tasks go under "misc"
+		groups := make(map[string][]string)
+		var groupNames []string
+		for _, taskName := range names {
+			group := config.Tasks[taskName].Group
+			if group == "" {
+				group = "misc"
+			}
+			if _, exists := groups[group]; !exists {
+				groupNames = append(groupNames, group)
+			}
+			groups[group] = append(groups[group], taskName)
 		}
+		sort.Strings(groupNames)
 
-		if len(task.Deps) > 0 {
-			fmt.Printf(" (depends on: %v)", task.Deps)
+		for _, group := range groupNames {
+			fmt.Fprintf(ui.Stdout, "📁 %s\n", group)
+			for _, taskName := range groups[group] {
+				printTask(taskName, config.Tasks[taskName])
+			}
+			fmt.Fprintln(ui.Stdout)
 		}
+	} else {
+		for _, taskName := range names {
+			printTask(taskName, config.Tasks[taskName])
+		}
+		fmt.Fprintln(ui.Stdout)
+	}
+
+	if hidden > 0 {
+		fmt.Fprintf(ui.Stdout, "🙈 %d internal task(s) hidden, use 't :list --all' to show them\n", hidden)
+	}
+	fmt.Fprintln(ui.Stdout, "💡 Run 't <task-name>' to execute a task")
+	for _, taskName := range names {
+		if config.Tasks[taskName].LongRunning {
+			fmt.Fprintln(ui.Stdout, "💡 Run long-running tasks in the background with 't :detach <task-name>'")
+			break
+		}
+	}
+}
+
+// printTask prints a single task line with its description and dependencies.
+// Long-running tasks, meant for :detach, get a rocket instead of a wrench.
+func printTask(taskName string, task runner.Task) {
+	marker := "🔧"
+	if task.LongRunning {
+		marker = "🚀"
+	}
+	fmt.Fprintf(ui.Stdout, "  %s %s", marker, taskName)
+
+	if task.Desc != "" {
+		fmt.Fprintf(ui.Stdout, " - %s", task.Desc)
+	}
+
+	if len(task.Deps) > 0 {
+		fmt.Fprintf(ui.Stdout, " (depends on: %v)", task.Deps)
+	}
+
+	if len(task.Labels) > 0 {
+		fmt.Fprintf(ui.Stdout, " %v", task.Labels)
+	}
 
-		fmt.Println()
+	if task.Global {
+		fmt.Fprint(ui.Stdout, " (global)")
 	}
 
-	fmt.Println()
-	fmt.Println("💡 Run 't <task-name>' to execute a task")
+	fmt.Fprintln(ui.Stdout)
 }
@@ -1,13 +1,21 @@
 package cmd
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"regexp"
 	"runtime"
 	"strconv"
+	"strings"
+	"time"
 
 	"t/internal/runner"
+	"t/internal/ui"
 
 	"github.com/spf13/cobra"
 )
@@ -16,28 +24,59 @@ var logsCmd = &cobra.Command{
 	Use:     ":logs <task-name-or-pid>",
 	Aliases: []string{":log", ":l", ":tail"},
 	Short:   "View logs of a detached task",
-	Long:    "Display the logs of a running or recently finished detached task.",
-	Args:    cobra.ExactArgs(1),
+	Long:    "Display the logs of a running or recently finished detached task, or with --all those of every detached task in one stream, each line prefixed with its task's name.",
+	Args: func(cmd *cobra.Command, args []string) error {
+		if all, _ := cmd.Flags().GetBool("all"); all {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
 	Run: func(cmd *cobra.Command, args []string) {
-		identifier := args[0]
-
 		// Load config (we need a runner instance)
-		config, err := runner.LoadConfig("tasks.yaml")
+		config, err := loadConfig()
 		if err != nil {
 			config = &runner.Config{} // Empty config
 		}
 
-		taskRunner := runner.NewRunner(config)
+		taskRunner := newRunner(config)
+
+		if all, _ := cmd.Flags().GetBool("all"); all {
+			if cmd.Flags().Changed("since") || cmd.Flags().Changed("reopen") {
+				fmt.Fprintln(ui.Stdout, "❌ --all can't be combined with --since or --reopen")
+				os.Exit(1)
+			}
+			var pattern *regexp.Regexp
+			if grep, _ := cmd.Flags().GetString("grep"); grep != "" {
+				if pattern, err = regexp.Compile(grep); err != nil {
+					fmt.Fprintf(ui.Stdout, "❌ Invalid --grep pattern: %v\n", err)
+					os.Exit(1)
+				}
+			}
+			lines, _ := cmd.Flags().GetInt("lines")
+			if lines < 1 {
+				fmt.Fprintln(ui.Stdout, "❌ --lines must be at least 1")
+				os.Exit(1)
+			}
+			follow, _ := cmd.Flags().GetBool("follow")
+			if err := showAllLogs(taskRunner, lines, pattern, follow); err != nil {
+				fmt.Fprintf(ui.Stdout, "❌ Error viewing logs: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		identifier := args[0]
 
 		// Get list of detached processes to find the log file
 		processes, err := taskRunner.ListDetachedProcesses()
 		if err != nil {
-			fmt.Printf("❌ Error listing detached processes: %v\n", err)
-			return
+			fmt.Fprintf(ui.Stdout, "❌ Error listing detached processes: %v\n", err)
+			os.Exit(1)
 		}
 
 		var logFile string
 		var taskName string
+		var found *runner.DetachedProcess
 
 		// Try to find by PID first
 		if pid, err := strconv.Atoi(identifier); err == nil {
@@ -45,6 +84,7 @@ var logsCmd = &cobra.Command{
 				if proc.PID == pid {
 					logFile = proc.LogFile
 					taskName = proc.TaskName
+					found = proc
 					break
 				}
 			}
@@ -54,46 +94,96 @@ var logsCmd = &cobra.Command{
 				if proc.TaskName == identifier {
 					logFile = proc.LogFile
 					taskName = proc.TaskName
+					found = proc
 					break
 				}
 			}
 		}
 
 		if logFile == "" {
-			fmt.Printf("❌ No detached task found with identifier: %s\n", identifier)
-			fmt.Println("\n💡 Use 't :ps' to see running detached tasks")
-			return
+			fmt.Fprintf(ui.Stdout, "❌ No detached task found with identifier: %s\n", identifier)
+			fmt.Fprintln(ui.Stdout, "\n💡 Use 't :ps' to see running detached tasks")
+			os.Exit(1)
+		}
+
+		// After a rotation the writer has to reopen the file before new
+		// output shows up in it
+		if reopen, _ := cmd.Flags().GetBool("reopen"); reopen {
+			if err := taskRunner.ReopenDetachedLog(strconv.Itoa(found.PID)); err != nil {
+				fmt.Fprintf(ui.Stdout, "❌ Error reopening log: %v\n", err)
+				os.Exit(1)
+			}
+			// Give the writer a moment to create the file again
+			time.Sleep(100 * time.Millisecond)
 		}
 
 		// Check if log file exists
 		if _, err := os.Stat(logFile); os.IsNotExist(err) {
-			fmt.Printf("❌ Log file not found: %s\n", logFile)
-			return
+			fmt.Fprintf(ui.Stdout, "❌ Log file not found: %s\n", logFile)
+			os.Exit(1)
 		}
 
-		fmt.Printf("📝 Logs for task '%s':\n", taskName)
-		fmt.Printf("📄 File: %s\n\n", logFile)
+		// Lines are filtered in Go, so --grep behaves the same on every platform
+		var pattern *regexp.Regexp
+		if grep, _ := cmd.Flags().GetString("grep"); grep != "" {
+			if pattern, err = regexp.Compile(grep); err != nil {
+				fmt.Fprintf(ui.Stdout, "❌ Invalid --grep pattern: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		lines, _ := cmd.Flags().GetInt("lines")
+		if lines < 1 {
+			fmt.Fprintln(ui.Stdout, "❌ --lines must be at least 1")
+			os.Exit(1)
+		}
+
+		fmt.Fprintf(ui.Stdout, "📝 Logs for task '%s':\n", taskName)
+		fmt.Fprintf(ui.Stdout, "📄 File: %s\n", logFile)
+		printSteps(found, "")
+		fmt.Fprintln(ui.Stdout)
 
 		// Follow flag for tail -f behavior
 		follow, _ := cmd.Flags().GetBool("follow")
 
+		if since, _ := cmd.Flags().GetString("since"); since != "" {
+			// All lines since then are shown unless -n is given
+			limit := 0
+			if cmd.Flags().Changed("lines") {
+				limit = lines
+			}
+			if err := showLogSince(logFile, since, follow, pattern, limit); err != nil {
+				fmt.Fprintf(ui.Stdout, "❌ Error viewing logs: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		if pattern != nil {
+			if err := showLogMatches(logFile, pattern, lines, follow); err != nil {
+				fmt.Fprintf(ui.Stdout, "❌ Error viewing logs: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
 		// Display logs using appropriate command for the platform
 		var tailCmd *exec.Cmd
 		if runtime.GOOS == "windows" {
 			if follow {
 				// PowerShell equivalent of tail -f
 				tailCmd = exec.Command("powershell", "-Command",
-					fmt.Sprintf("Get-Content '%s' -Wait -Tail 50", logFile))
+					fmt.Sprintf("Get-Content '%s' -Wait -Tail %d", logFile, lines))
 			} else {
-				// Show last 50 lines
+				// Show the last lines
 				tailCmd = exec.Command("powershell", "-Command",
-					fmt.Sprintf("Get-Content '%s' -Tail 50", logFile))
+					fmt.Sprintf("Get-Content '%s' -Tail %d", logFile, lines))
 			}
 		} else {
 			if follow {
-				tailCmd = exec.Command("tail", "-f", "-n", "50", logFile)
+				// -F keeps following the path when the log is rotated
+				tailCmd = exec.Command("tail", "-F", "-n", strconv.Itoa(lines), logFile)
 			} else {
-				tailCmd = exec.Command("tail", "-n", "50", logFile)
+				tailCmd = exec.Command("tail", "-n", strconv.Itoa(lines), logFile)
 			}
 		}
 
@@ -101,19 +191,239 @@ var logsCmd = &cobra.Command{
 		tailCmd.Stderr = os.Stderr
 
 		if follow {
-			fmt.Println("📡 Following logs (Press Ctrl+C to exit)...")
-			fmt.Println("─────────────────────────────────────────────")
+			fmt.Fprintln(ui.Stdout, "📡 Following logs (Press Ctrl+C to exit)...")
+			fmt.Fprintln(ui.Stdout, "─────────────────────────────────────────────")
 		} else {
-			fmt.Println("📋 Last 50 lines:")
-			fmt.Println("─────────────────────────────────────────────")
+			fmt.Fprintf(ui.Stdout, "📋 Last %d lines:\n", lines)
+			fmt.Fprintln(ui.Stdout, "─────────────────────────────────────────────")
 		}
 
 		if err := tailCmd.Run(); err != nil {
-			fmt.Printf("❌ Error viewing logs: %v\n", err)
+			fmt.Fprintf(ui.Stdout, "❌ Error viewing logs: %v\n", err)
+			os.Exit(1)
+		}
+		if info, err := os.Stat(logFile); err == nil {
+			saveLogOffset(logFile, info.Size())
 		}
 	},
 }
 
 func init() {
-	logsCmd.Flags().BoolP("follow", "f", false, "Follow log output (like tail -f)")
+	logsCmd.Flags().Bool("follow", false, "Follow log output (like tail -f)")
+	logsCmd.Flags().Bool("all", false, "Show the logs of every detached task together, each line prefixed with its task's name")
+	logsCmd.Flags().Bool("reopen", false, "Make the task reopen its log file first, after the file was rotated")
+	logsCmd.Flags().IntP("lines", "n", 50, "Number of lines to show")
+	logsCmd.Flags().String("grep", "", "Only show lines matching this regular expression")
+	logsCmd.Flags().String("since", "", "Only show lines since a duration ago (10m), a timestamp (RFC3339) or 'last' time logs were viewed")
+}
+
+// logOffsetsFile returns the file that remembers how much of each log file
+// in the same directory :logs has shown
+func logOffsetsFile(logFile string) string {
+	return filepath.Join(filepath.Dir(logFile), ".offsets.json")
+}
+
+// showLogSince prints the lines of a log file written since the --since
+// value, keeping only those matching pattern when it isn't nil and the last
+// limit of them when limit is above zero. Timestamps require
+// ':detach --timestamps'; logs without them fall back to everything written
+// since :logs last showed the file.
+func showLogSince(logFile string, since string, follow bool, pattern *regexp.Regexp, limit int) error {
+	file, err := os.Open(logFile)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return err
+	}
+
+	var output string
+	useOffset := since == "last"
+	if !useOffset {
+		start, err := parseSince(since)
+		if err != nil {
+			return err
+		}
+
+		var timestamped bool
+		output, timestamped = linesSince(string(data), start)
+		if !timestamped {
+			fmt.Fprintln(ui.Stdout, "⚠️  Log lines have no timestamps (start the task with ':detach --timestamps'), showing output since logs were last viewed")
+			useOffset = true
+		}
+	}
+
+	if useOffset {
+		offset := loadLogOffsets(logFile)[logFile]
+		if offset > int64(len(data)) {
+			// The log was recreated since it was last viewed
+			offset = 0
+		}
+		output = string(data[offset:])
+	}
+
+	if pattern != nil {
+		output = matchingLines(output, pattern)
+	}
+	if limit > 0 {
+		output = lastLines(output, limit)
+	}
+
+	fmt.Fprintf(ui.Stdout, "📋 Lines since %s:\n", since)
+	fmt.Fprintln(ui.Stdout, "─────────────────────────────────────────────")
+	fmt.Print(output)
+	saveLogOffset(logFile, int64(len(data)))
+
+	if follow {
+		fmt.Fprintln(ui.Stdout, "📡 Following logs (Press Ctrl+C to exit)...")
+		return followFrom(file, logOutput(pattern), nil)
+	}
+	return nil
+}
+
+// showLogMatches prints the last lines of a log file that match pattern,
+// then with follow keeps printing matching lines as they are written
+func showLogMatches(logFile string, pattern *regexp.Regexp, lines int, follow bool) error {
+	file, err := os.Open(logFile)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(ui.Stdout, "📋 Last %d lines matching %s:\n", lines, pattern)
+	fmt.Fprintln(ui.Stdout, "─────────────────────────────────────────────")
+	fmt.Print(lastLines(matchingLines(string(data), pattern), lines))
+	saveLogOffset(logFile, int64(len(data)))
+
+	if follow {
+		fmt.Fprintln(ui.Stdout, "📡 Following logs (Press Ctrl+C to exit)...")
+		return followFrom(file, logOutput(pattern), nil)
+	}
+	return nil
+}
+
+// logOutput returns where followed log data is written: stdout, through a
+// lineFilter when a --grep pattern is given
+func logOutput(pattern *regexp.Regexp) io.Writer {
+	if pattern == nil {
+		return os.Stdout
+	}
+	return &lineFilter{pattern: pattern, out: os.Stdout}
+}
+
+// matchingLines returns the lines of data that match pattern
+func matchingLines(data string, pattern *regexp.Regexp) string {
+	var b strings.Builder
+	filter := &lineFilter{pattern: pattern, out: &b}
+	filter.Write([]byte(data))
+	filter.Flush()
+	return b.String()
+}
+
+// lastLines returns the last n lines of data
+func lastLines(data string, n int) string {
+	lines := strings.SplitAfter(data, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "")
+}
+
+// lineFilter writes the lines that match pattern to out. A line is only
+// matched once it is complete, so lines written in pieces are handled.
+type lineFilter struct {
+	pattern *regexp.Regexp
+	out     io.Writer
+	partial []byte
+}
+
+func (f *lineFilter) Write(p []byte) (int, error) {
+	f.partial = append(f.partial, p...)
+	for {
+		end := bytes.IndexByte(f.partial, '\n')
+		if end < 0 {
+			break
+		}
+		line := f.partial[:end+1]
+		f.partial = f.partial[end+1:]
+		if f.pattern.Match(bytes.TrimRight(line, "\r\n")) {
+			if _, err := f.out.Write(line); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return len(p), nil
+}
+
+// Flush matches the last line when it doesn't end with a newline
+func (f *lineFilter) Flush() {
+	if len(f.partial) > 0 && f.pattern.Match(f.partial) {
+		f.out.Write(append(f.partial, '\n'))
+	}
+	f.partial = nil
+}
+
+// parseSince turns a --since value into a point in time: a duration before
+// now, or an RFC3339 or "2006-01-02 15:04:05" local timestamp
+func parseSince(value string) (time.Time, error) {
+	if duration, err := time.ParseDuration(value); err == nil {
+		return time.Now().Add(-duration), nil
+	}
+	if start, err := time.Parse(time.RFC3339, value); err == nil {
+		return start, nil
+	}
+	if start, err := time.ParseInLocation("2006-01-02 15:04:05", value, time.Local); err == nil {
+		return start, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q, use a duration like 10m, a timestamp or 'last'", value)
+}
+
+// linesSince returns the lines stamped at or after start. Lines without a
+// timestamp belong to the stamped line before them. The second result
+// reports whether any line had a timestamp.
+func linesSince(data string, start time.Time) (string, bool) {
+	var b strings.Builder
+	timestamped, keep := false, false
+	for _, line := range strings.SplitAfter(data, "\n") {
+		stamp, _, _ := strings.Cut(line, " ")
+		if at, err := time.Parse(time.RFC3339, stamp); err == nil {
+			timestamped = true
+			keep = !at.Before(start)
+		}
+		if keep {
+			b.WriteString(line)
+		}
+	}
+	return b.String(), timestamped
+}
+
+// loadLogOffsets reads the offsets saved next to a log file, or returns an
+// empty map
+func loadLogOffsets(logFile string) map[string]int64 {
+	offsets := make(map[string]int64)
+	if data, err := os.ReadFile(logOffsetsFile(logFile)); err == nil {
+		json.Unmarshal(data, &offsets)
+	}
+	return offsets
+}
+
+// saveLogOffset records how much of a log file has been shown. It is
+// best-effort: losing an offset only means showing more next time.
+func saveLogOffset(logFile string, offset int64) {
+	offsets := loadLogOffsets(logFile)
+	offsets[logFile] = offset
+	if data, err := json.Marshal(offsets); err == nil {
+		os.WriteFile(logOffsetsFile(logFile), data, 0644)
+	}
 }
@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"t/internal/runner"
+	"t/internal/ui"
+)
+
+// logRefreshTicks is how many polls of ':logs --all --follow' pass between
+// looks for tasks started since it began
+const logRefreshTicks = 5
+
+// logStream is a log file shown by ':logs --all', whose lines are written out
+// with the prefix of its task
+type logStream struct {
+	path   string
+	file   *os.File
+	prefix runner.LineWriter
+	filter *lineFilter
+}
+
+// Write passes log data through the --grep filter, if any, to the prefix
+func (s *logStream) Write(p []byte) (int, error) {
+	if s.filter != nil {
+		return s.filter.Write(p)
+	}
+	return s.prefix.Write(p)
+}
+
+// Close writes out a last line without a newline and closes the file
+func (s *logStream) Close() error {
+	if s.filter != nil {
+		s.filter.Flush()
+	}
+	s.prefix.Flush()
+	return s.file.Close()
+}
+
+// showAllLogs prints the last lines of the log of every detached task, each
+// line prefixed with its task's name, then with follow keeps printing the
+// lines they write, including those of tasks started meanwhile
+func showAllLogs(taskRunner *runner.Runner, lines int, pattern *regexp.Regexp, follow bool) error {
+	processes, err := taskRunner.ListDetachedProcesses()
+	if err != nil {
+		return err
+	}
+	if len(processes) == 0 && !follow {
+		fmt.Fprintln(ui.Stdout, "📭 No detached tasks running")
+		return nil
+	}
+	sortByTask(processes)
+
+	if pattern != nil {
+		fmt.Fprintf(ui.Stdout, "📋 Last %d lines matching %s of each task:\n", lines, pattern)
+	} else {
+		fmt.Fprintf(ui.Stdout, "📋 Last %d lines of each task:\n", lines)
+	}
+	fmt.Fprintln(ui.Stdout, "─────────────────────────────────────────────")
+
+	streams := make(map[string]*logStream)
+	defer func() {
+		for _, stream := range streams {
+			stream.Close()
+		}
+	}()
+
+	for _, proc := range processes {
+		stream, err := openLogStream(taskRunner, proc, processes, pattern)
+		if err != nil {
+			fmt.Fprintf(ui.Stdout, "⚠️  Skipping %s: %v\n", proc.TaskName, err)
+			continue
+		}
+		streams[proc.LogFile] = stream
+
+		data, err := io.ReadAll(stream.file)
+		if err != nil {
+			return err
+		}
+		output := string(data)
+		if pattern != nil {
+			output = matchingLines(output, pattern)
+		}
+		// Lines are prefixed here, as the filter already ran
+		stream.prefix.Write([]byte(lastLines(output, lines)))
+	}
+	if !follow {
+		return nil
+	}
+
+	fmt.Fprintln(ui.Stdout, "📡 Following logs (Press Ctrl+C to exit)...")
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+	for tick := 1; ; tick++ {
+		<-ticker.C
+
+		// Tasks started since are shown from the start of their log
+		if tick%logRefreshTicks == 0 {
+			if processes, err := taskRunner.ListDetachedProcesses(); err == nil {
+				for _, proc := range processes {
+					if streams[proc.LogFile] != nil {
+						continue
+					}
+					if stream, err := openLogStream(taskRunner, proc, processes, pattern); err == nil {
+						streams[proc.LogFile] = stream
+					}
+				}
+			}
+		}
+
+		for path, stream := range streams {
+			if _, err := io.Copy(stream, stream.file); err != nil {
+				return err
+			}
+			// A rotated log is read again from the start of the new file
+			if reopened := reopenRotated(stream); reopened != nil {
+				streams[path] = reopened
+			}
+		}
+	}
+}
+
+// openLogStream opens the log of a detached task for ':logs --all'. Its
+// prefix is the task's name, with the PID when several of processes run the
+// same task.
+func openLogStream(taskRunner *runner.Runner, proc *runner.DetachedProcess, processes []*runner.DetachedProcess, pattern *regexp.Regexp) (*logStream, error) {
+	file, err := os.Open(proc.LogFile)
+	if err != nil {
+		return nil, err
+	}
+
+	name := proc.TaskName
+	for _, other := range processes {
+		if other != proc && other.TaskName == proc.TaskName {
+			name += " " + strconv.Itoa(proc.PID)
+			break
+		}
+	}
+
+	stream := &logStream{
+		path:   proc.LogFile,
+		file:   file,
+		prefix: taskRunner.NewPrefixWriter(os.Stdout, name),
+	}
+	if pattern != nil {
+		stream.filter = &lineFilter{pattern: pattern, out: stream.prefix}
+	}
+	return stream, nil
+}
+
+// reopenRotated returns the stream reading the file now at the path of a
+// stream's log once the log was rotated, or nil when it wasn't
+func reopenRotated(stream *logStream) *logStream {
+	current, err := os.Stat(stream.path)
+	if err != nil {
+		return nil
+	}
+	opened, err := stream.file.Stat()
+	if err != nil || os.SameFile(current, opened) {
+		return nil
+	}
+
+	file, err := os.Open(stream.path)
+	if err != nil {
+		return nil
+	}
+	stream.file.Close()
+	reopened := *stream
+	reopened.file = file
+	return &reopened
+}
+
+// sortByTask orders detached processes by task name, then by start time
+func sortByTask(processes []*runner.DetachedProcess) {
+	sort.SliceStable(processes, func(i, j int) bool {
+		if processes[i].TaskName != processes[j].TaskName {
+			return processes[i].TaskName < processes[j].TaskName
+		}
+		return processes[i].StartedAt.Before(processes[j].StartedAt)
+	})
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"t/internal/runner"
+
+	"github.com/spf13/cobra"
+)
+
+// logWriterCmd writes the log file of a detached task. It copies stdin to
+// the file, with the secrets listed on the first line of stdin masked, and
+// reopens the file on SIGHUP or when it notices the file was moved or removed, so a log
+// rotated by logrotate keeps receiving output.
+var logWriterCmd = &cobra.Command{
+	Use:    ":log-writer <log-file>",
+	Short:  "Copy stdin to a log file, reopening it after rotation",
+	Hidden: true,
+	Args:   cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		timestamps, _ := cmd.Flags().GetBool("timestamps")
+
+		log := &reopeningFile{path: args[0]}
+		if err := log.open(); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Cannot open log file: %v\n", err)
+			os.Exit(1)
+		}
+		defer log.file.Close()
+
+		// The task's secrets are masked in the log, as in t's own output
+		input := bufio.NewReader(os.Stdin)
+		if err := runner.ReadLogSecrets(input); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Cannot read secrets to mask: %v\n", err)
+			os.Exit(1)
+		}
+		out := runner.NewMaskingStream(log)
+		defer out.Flush()
+
+		// HUP asks for a reopen instead of ending the writer, which ends once
+		// the task closes its output
+		hup := make(chan os.Signal, 1)
+		signal.Notify(hup, syscall.SIGHUP)
+		go func() {
+			for range hup {
+				if err := log.reopen(); err != nil {
+					fmt.Fprintf(os.Stderr, "⚠️  Cannot reopen log file: %v\n", err)
+				}
+			}
+		}()
+
+		if !timestamps {
+			io.Copy(out, input)
+			return
+		}
+		scanner := bufio.NewScanner(input)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			fmt.Fprintf(out, "%s %s\n", time.Now().Format(time.RFC3339), scanner.Text())
+		}
+	},
+}
+
+func init() {
+	logWriterCmd.Flags().Bool("timestamps", false, "Prefix each line with an RFC3339 timestamp")
+}
+
+// reopeningFile appends to the file at path, opening it again when asked
+// to or once the path no longer leads to the open file
+type reopeningFile struct {
+	path      string
+	file      *os.File
+	mutex     sync.Mutex
+	lastCheck time.Time
+}
+
+// open opens the file at path for appending, creating it if needed. If it
+// can't be opened, output keeps going to the file open before.
+func (f *reopeningFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	if f.file != nil {
+		f.file.Close()
+	}
+	f.file = file
+	return nil
+}
+
+// reopen opens the file at path again
+func (f *reopeningFile) reopen() error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return f.open()
+}
+
+// moved reports whether the path leads to another file than the open one,
+// checking at most once a second
+func (f *reopeningFile) moved() bool {
+	if time.Since(f.lastCheck) < time.Second {
+		return false
+	}
+	f.lastCheck = time.Now()
+
+	current, err := os.Stat(f.path)
+	if err != nil {
+		return true
+	}
+	open, err := f.file.Stat()
+	return err == nil && !os.SameFile(current, open)
+}
+
+// Write appends p to the file, reopening it first if it was moved
+func (f *reopeningFile) Write(p []byte) (int, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.moved() {
+		f.open()
+	}
+	return f.file.Write(p)
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"runtime"
+	"time"
+
+	"t/internal/ui"
+)
+
+// notification is the JSON payload posted to notify_url
+type notification struct {
+	Task       string `json:"task"`
+	Status     string `json:"status"`
+	Duration   string `json:"duration"`
+	DurationMs int64  `json:"duration_ms"`
+	Error      string `json:"error,omitempty"`
+}
+
+// notify reports the end of a run to the webhook at url, if any, and as a
+// desktop notification. It is best-effort: failures only print a warning.
+func notify(url string, taskName string, duration time.Duration, runErr error) {
+	payload := notification{
+		Task:       taskName,
+		Status:     "success",
+		Duration:   duration.Round(time.Millisecond).String(),
+		DurationMs: duration.Milliseconds(),
+	}
+	if runErr != nil {
+		payload.Status = "failed"
+		payload.Error = runErr.Error()
+	}
+
+	if url != "" {
+		if err := postNotification(url, payload); err != nil {
+			fmt.Fprintf(ui.Stdout, "⚠️  Could not send notification: %v\n", err)
+		}
+	}
+
+	message := fmt.Sprintf("Task %s %s after %s", taskName, payload.Status, payload.Duration)
+	desktopNotify("t", message)
+}
+
+// postNotification posts the payload as JSON to url
+func postNotification(url string, payload notification) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s returned %s", url, resp.Status)
+	}
+	return nil
+}
+
+// desktopNotify shows a native notification where a notifier is available:
+// notify-send on Linux and osascript on macOS. Other platforms are skipped.
+func desktopNotify(title, message string) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "linux":
+		if _, err := exec.LookPath("notify-send"); err != nil {
+			return
+		}
+		cmd = exec.Command("notify-send", title, message)
+	case "darwin":
+		script := fmt.Sprintf("display notification %q with title %q", message, title)
+		cmd = exec.Command("osascript", "-e", script)
+	default:
+		return
+	}
+
+	cmd.Run()
+}
@@ -4,7 +4,7 @@ import (
 	"fmt"
 	"time"
 
-	"t/internal/runner"
+	"t/internal/ui"
 
 	"github.com/spf13/cobra"
 )
@@ -19,24 +19,24 @@ var parallelCmd = &cobra.Command{
 		taskName := args[0]
 
 		start := time.Now()
-		fmt.Printf("⏱️  Starting task '%s' at %s\n", taskName, start.Format("15:04:05.000"))
+		fmt.Fprintf(ui.Stdout, "⏱️  Starting task '%s' at %s\n", taskName, start.Format("15:04:05.000"))
 
 		// Load config and run task
-		config, err := runner.LoadConfig("tasks.yaml")
+		config, err := loadConfig()
 		if err != nil {
-			fmt.Printf("❌ Error loading config: %v\n", err)
-			fmt.Println("\n💡 Tip: Run 't :init' to create a tasks.yaml file")
+			fmt.Fprintf(ui.Stdout, "❌ Error loading config: %v\n", err)
+			fmt.Fprintln(ui.Stdout, "\n💡 Tip: Run 't :init' to create a tasks.yaml file")
 			return
 		}
 
-		taskRunner := runner.NewRunner(config)
+		taskRunner := newRunner(config)
 
 		if err := taskRunner.RunTask(taskName); err != nil {
-			fmt.Printf("❌ Task failed: %v\n", err)
+			fmt.Fprintf(ui.Stdout, "❌ Task failed: %v\n", err)
 			return
 		}
 
 		duration := time.Since(start)
-		fmt.Printf("🎉 Task '%s' completed successfully in %v!\n", taskName, duration.Round(time.Millisecond))
+		fmt.Fprintf(ui.Stdout, "🎉 Task '%s' completed successfully in %v!\n", taskName, duration.Round(time.Millisecond))
 	},
 }
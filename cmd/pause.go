@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"t/internal/runner"
+	"t/internal/ui"
+
+	"github.com/spf13/cobra"
+)
+
+var pauseCmd = &cobra.Command{
+	Use:   ":pause <task-name|pid|log-file>",
+	Short: "Pause a running detached task",
+	Long:  "Pause detached tasks by task name, process ID (PID) or log file path, freeing the CPU they use without stopping them. The whole process group gets SIGSTOP and ':resume' lets it continue. Not supported on Windows.",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := detachedRunner().PauseDetachedProcess(args[0]); err != nil {
+			fmt.Fprintf(ui.Stdout, "❌ Error pausing process: %v\n", err)
+			fmt.Fprintln(ui.Stdout, "\n💡 Use 't :ps' to see running detached tasks")
+			os.Exit(1)
+		}
+	},
+}
+
+var resumeCmd = &cobra.Command{
+	Use:   ":resume <task-name|pid|log-file>",
+	Short: "Resume a paused detached task",
+	Long:  "Let detached tasks paused with ':pause' run again by sending SIGCONT to their process group. Not supported on Windows.",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := detachedRunner().ResumeDetachedProcess(args[0]); err != nil {
+			fmt.Fprintf(ui.Stdout, "❌ Error resuming process: %v\n", err)
+			fmt.Fprintln(ui.Stdout, "\n💡 Use 't :ps' to see running detached tasks")
+			os.Exit(1)
+		}
+	},
+}
+
+// detachedRunner returns a runner for managing detached tasks, which works
+// without a valid config
+func detachedRunner() *runner.Runner {
+	config, err := loadConfig()
+	if err != nil {
+		config = &runner.Config{} // Empty config
+	}
+	return newRunner(config)
+}
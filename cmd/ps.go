@@ -1,10 +1,12 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"time"
 
 	"t/internal/runner"
+	"t/internal/ui"
 
 	"github.com/spf13/cobra"
 )
@@ -16,41 +18,126 @@ var psCmd = &cobra.Command{
 	Long:    "Show all currently running detached tasks with their PIDs, start times, and log files.",
 	Run: func(cmd *cobra.Command, args []string) {
 		// Load config (we need a runner instance to access the methods)
-		config, err := runner.LoadConfig("tasks.yaml")
+		config, err := loadConfig()
 		if err != nil {
 			// For listing processes, we don't strictly need a valid config
 			// But we need a runner instance
-			fmt.Printf("⚠️  Warning: Could not load tasks.yaml, showing tracked processes only\n")
+			if asJSON, _ := cmd.Flags().GetBool("json"); !asJSON {
+				fmt.Fprintf(ui.Stdout, "⚠️  Warning: Could not load tasks.yaml, showing tracked processes only\n")
+			}
 			config = &runner.Config{} // Empty config
 		}
 
-		taskRunner := runner.NewRunner(config)
+		taskRunner := newRunner(config)
 
 		// Get list of detached processes
 		processes, err := taskRunner.ListDetachedProcesses()
 		if err != nil {
-			fmt.Printf("❌ Error listing detached processes: %v\n", err)
+			fmt.Fprintf(ui.Stdout, "❌ Error listing detached processes: %v\n", err)
+			return
+		}
+
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			printProcessesJSON(processes)
 			return
 		}
 
 		if len(processes) == 0 {
-			fmt.Println("📭 No detached tasks are currently running")
-			fmt.Println("\n💡 Start a detached task with: t :detach <task-name>")
+			fmt.Fprintln(ui.Stdout, "📭 No detached tasks are currently running")
+			fmt.Fprintln(ui.Stdout, "\n💡 Start a detached task with: t :detach <task-name>")
 			return
 		}
 
-		fmt.Printf("🔧 Running detached tasks (%d):\n\n", len(processes))
+		fmt.Fprintf(ui.Stdout, "🔧 Running detached tasks (%d):\n\n", len(processes))
 
 		for _, proc := range processes {
 			duration := time.Since(proc.StartedAt).Round(time.Second)
-			fmt.Printf("  📋 Task: %s\n", proc.TaskName)
-			fmt.Printf("     🆔 PID: %d\n", proc.PID)
-			fmt.Printf("     ⏰ Running for: %v\n", duration)
-			fmt.Printf("     📝 Log file: %s\n", proc.LogFile)
-			fmt.Printf("     🛑 Stop with: t :stop %s\n\n", proc.TaskName)
+			fmt.Fprintf(ui.Stdout, "  📋 Task: %s\n", proc.TaskName)
+			fmt.Fprintf(ui.Stdout, "     🆔 PID: %d\n", proc.PID)
+			fmt.Fprintf(ui.Stdout, "     ⏰ Running for: %v\n", duration)
+			if proc.ScheduledAt != nil && proc.ScheduledAt.After(time.Now()) {
+				fmt.Fprintf(ui.Stdout, "     ⏳ Scheduled for: %s (in %v)\n", proc.ScheduledAt.Format("2006-01-02 15:04:05"), time.Until(*proc.ScheduledAt).Round(time.Second))
+			}
+			if proc.State == runner.ProcessPaused {
+				fmt.Fprintf(ui.Stdout, "     ⏸️  State: paused (resume with: t :resume %s)\n", proc.TaskName)
+			}
+			if usage, err := runner.ProcessUsage(proc.PID); err == nil {
+				fmt.Fprintf(ui.Stdout, "     📈 CPU: %s  Memory: %s\n", formatCPU(usage.CPUPercent), formatBytes(usage.RSSBytes))
+			}
+			fmt.Fprintf(ui.Stdout, "     📝 Log file: %s\n", proc.LogFile)
+			printSteps(proc, "     ")
+			fmt.Fprintf(ui.Stdout, "     🛑 Stop with: t :stop %s\n\n", proc.TaskName)
 		}
 
-		fmt.Printf("💡 Use 't :stop <task-name>' or 't :stop <pid>' to stop a task\n")
-		fmt.Printf("💡 Use 't :logs <task-name>' to view logs\n")
+		fmt.Fprintf(ui.Stdout, "💡 Use 't :stop <task-name>' or 't :stop <pid>' to stop a task\n")
+		fmt.Fprintf(ui.Stdout, "💡 Use 't :logs <task-name>' to view logs\n")
 	},
 }
+
+func init() {
+	psCmd.Flags().Bool("json", false, "Print the processes as JSON, including CPU and memory usage")
+}
+
+// processInfo is a detached process with its resource usage, as printed by
+// :ps --json
+type processInfo struct {
+	*runner.DetachedProcess
+	*runner.Usage
+}
+
+// printProcessesJSON prints the processes and their usage as a JSON array.
+// Usage fields are left out for processes whose usage can't be read.
+func printProcessesJSON(processes []*runner.DetachedProcess) {
+	infos := make([]processInfo, 0, len(processes))
+	for _, proc := range processes {
+		usage, _ := runner.ProcessUsage(proc.PID)
+		infos = append(infos, processInfo{DetachedProcess: proc, Usage: usage})
+	}
+
+	data, err := json.MarshalIndent(infos, "", "  ")
+	if err != nil {
+		fmt.Fprintf(ui.Stdout, "❌ Error encoding processes: %v\n", err)
+		return
+	}
+	fmt.Println(string(data))
+}
+
+// printSteps prints the resolved command sequence of a detached process.
+// Entries saved before steps were recorded only have the main command.
+func printSteps(proc *runner.DetachedProcess, indent string) {
+	steps := proc.Steps
+	if len(steps) == 0 {
+		steps = []string{proc.Command}
+	}
+
+	fmt.Fprintf(ui.Stdout, "%s➡️  Commands:\n", indent)
+	for i, step := range steps {
+		if i < len(steps)-1 {
+			fmt.Fprintf(ui.Stdout, "%s   %d. %s (setup)\n", indent, i+1, step)
+		} else {
+			fmt.Fprintf(ui.Stdout, "%s   %d. %s\n", indent, i+1, step)
+		}
+	}
+}
+
+// formatCPU formats a CPU percentage, or n/a when it is unknown
+func formatCPU(percent float64) string {
+	if percent < 0 {
+		return "n/a"
+	}
+	return fmt.Sprintf("%.1f%%", percent)
+}
+
+// formatBytes formats a byte count with a binary unit, e.g. 12.3 MiB
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -4,10 +4,17 @@ Copyright © 2025 Mohamed Eid <medoeid50@gmail.com>
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
 
 	"t/internal/runner"
+	"t/internal/ui"
 
 	"github.com/spf13/cobra"
 )
@@ -26,9 +33,41 @@ Examples:
   t build         Run the build task
   t test          Run the test task
   t <task-name>   Run any task defined in tasks.yaml
+  t -C dir build  Run the build task as if started in dir
 
 Note: Tool commands start with ':' to avoid conflicts with user-defined tasks.`,
 	Args: cobra.MaximumNArgs(1),
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		color, _ := cmd.Flags().GetString("color")
+		if err := ui.Configure(color); err != nil {
+			fmt.Fprintf(ui.Stdout, "❌ %v\n", err)
+			os.Exit(1)
+		}
+
+		// The budget starts now and covers everything the command runs
+		if maxRuntime, _ := cmd.Flags().GetDuration("max-runtime"); maxRuntime > 0 {
+			runContext, cancelRun = context.WithTimeout(context.Background(), maxRuntime)
+		}
+
+		if loose, _ := cmd.Flags().GetBool("loose"); loose {
+			runner.StrictConfig = false
+		}
+
+		// Change directory before any command looks for tasks.yaml
+		if dir, _ := cmd.Flags().GetString("chdir"); dir != "" {
+			if err := os.Chdir(dir); err != nil {
+				fmt.Fprintf(ui.Stdout, "❌ Error changing directory: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		// :init creates a config where it runs, even below another project
+		if cmd != initCmd {
+			if err := enterProjectRoot(); err != nil {
+				fmt.Fprintf(ui.Stdout, "❌ Error finding the project root: %v\n", err)
+				os.Exit(1)
+			}
+		}
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 		if len(args) == 0 {
 			// Show help when no task is specified
@@ -39,41 +78,251 @@ Note: Tool commands start with ':' to avoid conflicts with user-defined tasks.`,
 		taskName := args[0]
 
 		// Load config and run task
-		config, err := runner.LoadConfig("tasks.yaml")
+		config, err := loadConfig()
 		if err != nil {
-			fmt.Printf("❌ Error loading config: %v\n", err)
-			fmt.Println("\n💡 Tip: Run 't :init' to create a tasks.yaml file")
+			fmt.Fprintf(ui.Stdout, "❌ Error loading config: %v\n", err)
+			if !errors.Is(err, runner.ErrUnknownProfile) {
+				fmt.Fprintln(ui.Stdout, "\n💡 Tip: Run 't :init' to create a tasks.yaml file")
+			}
 			os.Exit(1)
 		}
 
-		taskRunner := runner.NewRunner(config)
+		taskRunner := newRunner(config)
+		taskRunner.ContinueOnError, _ = cmd.Flags().GetBool("continue")
+		if failFast, _ := cmd.Flags().GetBool("fail-fast"); !failFast {
+			taskRunner.ContinueOnError = true
+		}
+		taskRunner.Parallel, _ = cmd.Flags().GetInt("parallel")
+		taskRunner.Force, _ = cmd.Flags().GetBool("force")
+		taskRunner.ForceAll, _ = cmd.Flags().GetBool("force-all")
+		taskRunner.NoDeps, _ = cmd.Flags().GetBool("no-deps")
+		taskRunner.AssumeYes, _ = cmd.Flags().GetBool("yes")
+		taskRunner.Timeout, _ = cmd.Flags().GetDuration("timeout")
+		taskRunner.IdleTimeout, _ = cmd.Flags().GetDuration("idle-timeout")
+		taskRunner.WaitForLock, _ = cmd.Flags().GetBool("wait")
+		showSummary, _ := cmd.Flags().GetBool("summary")
+		repeat, _ := cmd.Flags().GetInt("repeat")
+		untilFail, _ := cmd.Flags().GetBool("until-fail")
 
-		if err := taskRunner.RunTask(taskName); err != nil {
-			fmt.Printf("❌ Task failed: %v\n", err)
-			os.Exit(1)
+		if explain, _ := cmd.Flags().GetBool("explain"); explain {
+			explainTask(taskRunner, taskName)
+			return
+		}
+
+		profile, _ := cmd.Flags().GetString("profile")
+		if profile != "" {
+			taskRunner.Trace = runner.NewTrace()
+		}
+
+		// The report file is created up front so a bad path fails before
+		// anything runs
+		reporter, _ := cmd.Flags().GetString("reporter")
+		reportFile, _ := cmd.Flags().GetString("report-file")
+		var reportOut *os.File
+		if reporter != "" {
+			if !slices.Contains(runner.ReporterNames, reporter) {
+				fmt.Fprintf(ui.Stdout, "❌ Unknown reporter '%s' (available: %s)\n", reporter, strings.Join(runner.ReporterNames, ", "))
+				os.Exit(1)
+			}
+			if reportFile == "" {
+				fmt.Fprintln(ui.Stdout, "❌ --reporter needs --report-file")
+				os.Exit(1)
+			}
+			if reportOut, err = os.Create(reportFile); err != nil {
+				fmt.Fprintf(ui.Stdout, "❌ Could not create report file: %v\n", err)
+				os.Exit(1)
+			}
+			taskRunner.Reporter, _ = runner.NewReporter(reporter, reportOut)
+		}
+
+		start := time.Now()
+		if repeat > 0 || untilFail {
+			_, err = taskRunner.RepeatTask(taskName, repeat)
+		} else {
+			err = taskRunner.RunTask(taskName)
+		}
+		if showSummary {
+			printSummary(taskRunner, time.Since(start))
+		}
+		if profile != "" {
+			if traceErr := taskRunner.Trace.WriteFile(profile); traceErr != nil {
+				fmt.Fprintf(ui.Stdout, "⚠️  Could not write profile: %v\n", traceErr)
+			} else {
+				fmt.Fprintf(ui.Stdout, "📄 Profile written to %s (open it in chrome://tracing or ui.perfetto.dev)\n", profile)
+			}
+		}
+		if reporter != "" {
+			reportErr := taskRunner.Reporter.Finish()
+			if closeErr := reportOut.Close(); reportErr == nil {
+				reportErr = closeErr
+			}
+			if reportErr != nil {
+				fmt.Fprintf(ui.Stdout, "⚠️  Could not write report: %v\n", reportErr)
+			} else {
+				fmt.Fprintf(ui.Stdout, "📄 Report written to %s\n", reportFile)
+			}
+		}
+		if config.History {
+			entry := runner.HistoryEntry{StartedAt: start, Task: taskName, Duration: time.Since(start)}
+			if err != nil {
+				entry.ExitCode = exitCode(err)
+				entry.Error = err.Error()
+			}
+			if historyErr := taskRunner.AppendHistory(entry); historyErr != nil {
+				fmt.Fprintf(ui.Stdout, "⚠️  Could not record run history: %v\n", historyErr)
+			}
+		}
+		if notifyDone, _ := cmd.Flags().GetBool("notify"); notifyDone {
+			notify(config.NotifyURL, taskName, time.Since(start), err)
 		}
 
-		fmt.Printf("🎉 Task '%s' completed successfully!\n", taskName)
+		if err != nil {
+			fmt.Fprintf(ui.Stdout, "❌ Task failed: %v\n", err)
+			if errors.Is(err, runner.ErrTaskNotFound) {
+				fmt.Fprintln(ui.Stdout, "\n💡 Use 't :list' to see available tasks")
+			}
+			if errors.Is(err, runner.ErrMissingRequirement) {
+				fmt.Fprintln(ui.Stdout, "\n💡 Install the missing tools or add them to PATH, 't :doctor' checks every task")
+			}
+			if errors.Is(err, runner.ErrInputDisabled) {
+				fmt.Fprintln(ui.Stdout, "\n💡 Run the task from a terminal without --interactive=false to answer its prompts")
+			}
+			os.Exit(exitCode(err))
+		}
+
+		fmt.Fprintf(ui.Stdout, "🎉 Task '%s' completed successfully!\n", taskName)
 	},
 }
 
+// exitCode picks the process exit code for a failed run: the exit code of the
+// failing command when there is one, 1 otherwise
+func exitCode(err error) int {
+	var taskErr *runner.TaskFailedError
+	if errors.As(err, &taskErr) && taskErr.ExitCode > 0 {
+		return taskErr.ExitCode
+	}
+
+	return 1
+}
+
+// printSummary prints a table of every task touched by the run and its duration
+func printSummary(taskRunner *runner.Runner, total time.Duration) {
+	fmt.Fprintln(ui.Stdout)
+	fmt.Fprintln(ui.Stdout, "📊 Summary:")
+
+	w := tabwriter.NewWriter(ui.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  TASK\tDURATION\tSTATUS")
+	for _, timing := range taskRunner.Timings {
+		switch {
+		case timing.WhenFalse:
+			fmt.Fprintf(w, "  %s\t-\tskipped (when=false)\n", timing.Task)
+		case timing.Skipped:
+			fmt.Fprintf(w, "  %s\t-\tskipped (already ran)\n", timing.Task)
+		case timing.Failed:
+			fmt.Fprintf(w, "  %s\t%v\tfailed\n", timing.Task, timing.Duration.Round(time.Millisecond))
+		default:
+			fmt.Fprintf(w, "  %s\t%v\tok\n", timing.Task, timing.Duration.Round(time.Millisecond))
+		}
+	}
+	fmt.Fprintf(w, "  TOTAL\t%v\t\n", total.Round(time.Millisecond))
+	w.Flush()
+	fmt.Fprintln(ui.Stdout)
+}
+
+// explainTask prints why each task in the plan for taskName will run, be
+// skipped or fail, without running anything
+func explainTask(taskRunner *runner.Runner, taskName string) {
+	decisions, err := taskRunner.Explain(taskName)
+	if err != nil {
+		fmt.Fprintf(ui.Stdout, "❌ Cannot plan task: %v\n", err)
+		if errors.Is(err, runner.ErrTaskNotFound) {
+			fmt.Fprintln(ui.Stdout, "\n💡 Use 't :list' to see available tasks")
+		}
+		os.Exit(1)
+	}
+
+	fmt.Fprintf(ui.Stdout, "🧭 Plan for %s:\n", taskName)
+	for _, decision := range decisions {
+		indent := strings.Repeat("  ", decision.Depth+1)
+		fmt.Fprintf(ui.Stdout, "%s%s: %s\n", indent, decision.Task, decision.Reason)
+	}
+}
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
 	err := rootCmd.Execute()
+	if cancelRun != nil {
+		cancelRun()
+	}
 	if err != nil {
 		os.Exit(1)
 	}
 }
 
 func init() {
+	rootCmd.PersistentFlags().StringVarP(&configFile, "file", "f", "", "Config file to use, '-' for stdin or an http(s) URL (default $TASKS_FILE, then tasks.yaml, tasks.yml or .tasks.yaml)")
+	rootCmd.PersistentFlags().BoolVar(&allowRemote, "allow-remote", false, "Allow loading the config file from an http(s) URL")
+	rootCmd.PersistentFlags().StringP("chdir", "C", "", "Change to this directory before doing anything")
+	rootCmd.PersistentFlags().String("color", "auto", "Use emoji markers: auto, always or never (NO_COLOR is honored)")
+	rootCmd.PersistentFlags().Bool("loose", false, "Ignore unknown keys in the config file instead of failing")
+	rootCmd.PersistentFlags().BoolP("yes", "y", false, "Answer yes to all confirmation prompts")
+	rootCmd.PersistentFlags().BoolVar(&interactive, "interactive", true, "Prompt for interactive input; --interactive=false fails tasks that would prompt, for CI")
+	rootCmd.PersistentFlags().BoolVar(&noGlobal, "no-global", false, "Ignore the user-global config ($XDG_CONFIG_HOME/t/tasks.yaml or ~/.config/t/tasks.yaml)")
+	rootCmd.PersistentFlags().Duration("max-runtime", 0, "Cancel the whole run, killing running commands, once it takes longer than this (e.g. 10m)")
+	rootCmd.PersistentFlags().StringVar(&configProfile, "config-profile", "", "Apply the vars and env of this profile from the config's profiles section")
+	rootCmd.PersistentFlags().StringVar(&stateDir, "state-dir", "", "Directory for logs, the process registry and history, 'xdg' for one under $XDG_STATE_HOME (default .t-logs and .t-processes)")
+	rootCmd.PersistentFlags().StringVar(&shellFlags, "shell-flags", "", "Arguments to run cmd strings with, ending with the command flag (e.g. '-euo pipefail -c'); overrides shell_args")
+	rootCmd.PersistentFlags().BoolVar(&noPrefix, "no-prefix", false, "Don't prefix the output lines of dependencies running in parallel with their name")
+	rootCmd.PersistentFlags().BoolVar(&groupOutput, "group-output", false, "Buffer the output of dependencies running in parallel and print it task by task once each finishes")
+	rootCmd.PersistentFlags().BoolVar(&verbose, "verbose", false, "Report extra details, such as which config file is used")
+	rootCmd.PersistentFlags().StringArrayVar(&envFiles, "env-file", nil, "Load environment variables from a KEY=VALUE file (repeatable, later files win)")
+
+	rootCmd.Flags().Bool("summary", false, "Print a per-task timing summary after the run")
+	rootCmd.Flags().Bool("explain", false, "Show why each task would run or be skipped, without running anything")
+	rootCmd.Flags().String("profile", "", "Write a Chrome trace of task and command timings to this file")
+	rootCmd.Flags().String("reporter", "", "Write the task results as "+strings.Join(runner.ReporterNames, " or ")+" to --report-file")
+	rootCmd.Flags().String("report-file", "", "File the --reporter report is written to")
+	rootCmd.Flags().Bool("notify", false, "Send a desktop notification, and post to notify_url if set, when the run ends")
+	rootCmd.Flags().IntP("parallel", "p", 0, "Run up to this many matrix combinations, --repeat iterations or commands of parallel tasks at the same time")
+	rootCmd.Flags().Int("repeat", 0, "Run the task up to this many times, stopping at the first failure")
+	rootCmd.Flags().Bool("until-fail", false, "Run the task again and again until it fails (capped by --repeat if given)")
+	rootCmd.Flags().Duration("timeout", 0, "Kill a task that runs longer than this (e.g. 10m), unless it sets its own timeout; overrides the config's timeout")
+	rootCmd.Flags().Duration("idle-timeout", 0, "Kill a command that produces no output for this long (e.g. 2m); a task's idle_timeout wins")
+	rootCmd.Flags().Bool("wait", false, "Wait for the lock of a task with lock set when another run holds it, instead of failing")
+	rootCmd.Flags().Bool("force", false, "Run the task even if it already ran in this invocation")
+	rootCmd.Flags().Bool("force-all", false, "Run every task each time it is requested, including shared dependencies")
+	rootCmd.Flags().Bool("no-deps", false, "Run only the task's own commands, skipping its deps")
+	rootCmd.Flags().Bool("continue", false, "Keep running independent dependencies after a failure and report all failures")
+	rootCmd.Flags().Bool("fail-fast", true, "Fail with the first dependency failure; --fail-fast=false reports all failures like --continue")
+
+	rootCmd.SetHelpFunc(taskHelp(rootCmd.HelpFunc()))
+
 	// Add subcommands
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(initCmd)
 	rootCmd.AddCommand(listCmd)
+	rootCmd.AddCommand(describeCmd)
+	rootCmd.AddCommand(findCmd)
+	rootCmd.AddCommand(treeCmd)
+	rootCmd.AddCommand(whichCmd)
+	rootCmd.AddCommand(showConfigCmd)
+	rootCmd.AddCommand(schemaCmd)
+	rootCmd.AddCommand(exportCmd)
+	rootCmd.AddCommand(importCmd)
+	rootCmd.AddCommand(doctorCmd)
+	rootCmd.AddCommand(historyCmd)
 	rootCmd.AddCommand(parallelCmd)
+	rootCmd.AddCommand(benchCmd)
+	rootCmd.AddCommand(runLabelCmd)
 	rootCmd.AddCommand(detachCmd)
+	rootCmd.AddCommand(atCmd)
+	rootCmd.AddCommand(runAtCmd)
 	rootCmd.AddCommand(psCmd)
 	rootCmd.AddCommand(stopCmd)
+	rootCmd.AddCommand(pauseCmd)
+	rootCmd.AddCommand(resumeCmd)
 	rootCmd.AddCommand(logsCmd)
+	rootCmd.AddCommand(attachCmd)
+	rootCmd.AddCommand(logWriterCmd)
 }
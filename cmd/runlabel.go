@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"t/internal/ui"
+
+	"github.com/spf13/cobra"
+)
+
+var runLabelCmd = &cobra.Command{
+	Use:     ":run-label <label>",
+	Aliases: []string{":rl"},
+	Short:   "Run every task carrying a label",
+	Long:    "Run all tasks that carry the given label, one after another or several at once with --parallel. Dependencies shared between them run only once. Every task runs even if another fails, and a summary of the results is printed at the end.",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		label := args[0]
+
+		config, err := loadConfig()
+		if err != nil {
+			fmt.Fprintf(ui.Stdout, "❌ Error loading config: %v\n", err)
+			fmt.Fprintln(ui.Stdout, "\n💡 Tip: Run 't :init' to create a tasks.yaml file")
+			os.Exit(1)
+		}
+
+		names := config.TasksWithLabel(label)
+		if len(names) == 0 {
+			fmt.Fprintf(ui.Stdout, "❌ No tasks found with label '%s'\n", label)
+			fmt.Fprintln(ui.Stdout, "\n💡 Use 't :list' to see tasks and their labels")
+			os.Exit(1)
+		}
+
+		taskRunner := newRunner(config)
+		taskRunner.Parallel, _ = cmd.Flags().GetInt("parallel")
+		taskRunner.AssumeYes, _ = cmd.Flags().GetBool("yes")
+
+		fmt.Fprintf(ui.Stdout, "🏷️  Running %d task(s) labelled '%s': %v\n", len(names), label, names)
+		start := time.Now()
+		results := taskRunner.RunTasks(names)
+
+		fmt.Fprintln(ui.Stdout)
+		fmt.Fprintf(ui.Stdout, "📊 Results for label '%s':\n", label)
+		w := tabwriter.NewWriter(ui.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "  TASK\tDURATION\tSTATUS")
+		failed := 0
+		for _, result := range results {
+			status := "ok"
+			if result.Err != nil {
+				failed++
+				status = fmt.Sprintf("failed: %v", result.Err)
+			}
+			fmt.Fprintf(w, "  %s\t%v\t%s\n", result.Task, result.Duration.Round(time.Millisecond), status)
+		}
+		fmt.Fprintf(w, "  TOTAL\t%v\t\n", time.Since(start).Round(time.Millisecond))
+		w.Flush()
+		fmt.Fprintln(ui.Stdout)
+
+		if failed > 0 {
+			fmt.Fprintf(ui.Stdout, "❌ %d of %d task(s) failed\n", failed, len(results))
+			os.Exit(1)
+		}
+		fmt.Fprintf(ui.Stdout, "🎉 All %d task(s) labelled '%s' completed successfully!\n", len(results), label)
+	},
+}
+
+func init() {
+	runLabelCmd.Flags().IntP("parallel", "p", 0, "Run up to this many of the labelled tasks at the same time")
+}
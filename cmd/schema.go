@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"t/internal/runner"
+	"t/internal/ui"
+
+	"github.com/spf13/cobra"
+)
+
+var schemaCmd = &cobra.Command{
+	Use:   ":schema",
+	Short: "Print a JSON Schema of tasks.yaml",
+	Long:  "Print a JSON Schema (draft-07) of the config format, generated from t's own config types. Save it and point your editor's YAML support at it for completion and checks, e.g. 't :schema > .t/tasks.schema.json'.",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		data, err := json.MarshalIndent(runner.ConfigSchema(), "", "  ")
+		if err != nil {
+			fmt.Fprintf(ui.Stdout, "❌ Error encoding schema: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(data))
+	},
+}
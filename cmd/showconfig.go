@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"t/internal/runner"
+	"t/internal/ui"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var showConfigCmd = &cobra.Command{
+	Use:   ":config",
+	Short: "Print the effective configuration",
+	Long:  "Print the configuration the runner sees: the project config merged over the user-global config, with the --config-profile applied and the vars of each task expanded.",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		asJSON, _ := cmd.Flags().GetBool("json")
+
+		config, err := loadConfig()
+		if err != nil {
+			fmt.Fprintf(ui.Stdout, "❌ Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Show task vars with their templates expanded, the way commands see them
+		taskRunner := newRunner(config)
+		effective := *config
+		effective.Tasks = make(map[string]runner.Task, len(config.Tasks))
+		for taskName, task := range config.Tasks {
+			if len(task.Vars) > 0 {
+				vars, err := taskRunner.EffectiveVars(taskName)
+				if err != nil {
+					fmt.Fprintf(ui.Stdout, "❌ Error expanding vars of task %s: %v\n", taskName, err)
+					os.Exit(1)
+				}
+				resolved := make(map[string]string, len(task.Vars))
+				for name := range task.Vars {
+					resolved[name] = vars[name]
+				}
+				task.Vars = resolved
+			}
+			effective.Tasks[taskName] = task
+		}
+
+		var buf bytes.Buffer
+		encoder := yaml.NewEncoder(&buf)
+		encoder.SetIndent(2)
+		err = encoder.Encode(&effective)
+		data := buf.Bytes()
+		if err == nil && asJSON {
+			// Going through YAML keeps the keys and short forms of tasks.yaml
+			var generic any
+			if err = yaml.Unmarshal(data, &generic); err == nil {
+				data, err = json.MarshalIndent(generic, "", "  ")
+				data = append(data, '\n')
+			}
+		}
+		if err != nil {
+			fmt.Fprintf(ui.Stdout, "❌ Error encoding config: %v\n", err)
+			os.Exit(1)
+		}
+
+		os.Stdout.Write(data)
+	},
+}
+
+func init() {
+	showConfigCmd.Flags().Bool("json", false, "Print the configuration as JSON")
+}
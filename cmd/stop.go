@@ -4,36 +4,53 @@ import (
 	"fmt"
 
 	"t/internal/runner"
+	"t/internal/ui"
 
 	"github.com/spf13/cobra"
 )
 
 var stopCmd = &cobra.Command{
-	Use:     ":stop <task-name-or-pid>",
+	Use:     ":stop <task-name|pid|log-file>",
 	Aliases: []string{":kill", ":terminate", ":s"},
 	Short:   "Stop a running detached task",
-	Long:    "Stop a detached task by task name or process ID (PID).",
+	Long:    "Stop detached tasks by task name, process ID (PID) or log file path. A task name stops every running instance of that task. Each process is asked to shut down gracefully and is killed if it is still running after the grace period. With --signal, the given signal is sent instead and the process keeps its registry entry.",
 	Args:    cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		identifier := args[0]
 
 		// Load config (we need a runner instance)
-		config, err := runner.LoadConfig("tasks.yaml")
+		config, err := loadConfig()
 		if err != nil {
 			// For stopping processes, we don't strictly need a valid config
 			config = &runner.Config{} // Empty config
 		}
 
-		taskRunner := runner.NewRunner(config)
+		taskRunner := newRunner(config)
+
+		// Send a single signal and leave the process running
+		if signal, _ := cmd.Flags().GetString("signal"); signal != "" {
+			if err := taskRunner.SignalDetachedProcess(identifier, signal); err != nil {
+				fmt.Fprintf(ui.Stdout, "❌ Error signalling process: %v\n", err)
+				fmt.Fprintln(ui.Stdout, "\n💡 Use 't :ps' to see running detached tasks")
+			}
+			return
+		}
+
+		grace, _ := cmd.Flags().GetDuration("grace")
 
 		// Stop the detached process
-		err = taskRunner.StopDetachedProcess(identifier)
+		err = taskRunner.StopDetachedProcess(identifier, grace)
 		if err != nil {
-			fmt.Printf("❌ Error stopping process: %v\n", err)
-			fmt.Println("\n💡 Use 't :ps' to see running detached tasks")
+			fmt.Fprintf(ui.Stdout, "❌ Error stopping process: %v\n", err)
+			fmt.Fprintln(ui.Stdout, "\n💡 Use 't :ps' to see running detached tasks")
 			return
 		}
 
 		// Success message is printed in StopDetachedProcess
 	},
 }
+
+func init() {
+	stopCmd.Flags().String("signal", "", "Send this signal (e.g. HUP, SIGUSR1 or 10) instead of stopping the process")
+	stopCmd.Flags().Duration("grace", runner.DefaultStopGrace, "Time to wait for a graceful shutdown before killing the process")
+}
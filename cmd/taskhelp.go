@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"t/internal/runner"
+	"t/internal/ui"
+
+	"github.com/spf13/cobra"
+)
+
+// taskHelp prints the help of a task, for 't <task> --help'. Other help
+// requests go to the default help.
+func taskHelp(defaultHelp func(*cobra.Command, []string)) func(*cobra.Command, []string) {
+	return func(cmd *cobra.Command, args []string) {
+		if cmd != rootCmd || cmd.Flags().NArg() == 0 {
+			defaultHelp(cmd, args)
+			return
+		}
+		// Help skips the pre-run, which applies --chdir and --color
+		cmd.PersistentPreRun(cmd, args)
+		printTaskHelp(cmd.Flags().Arg(0))
+	}
+}
+
+// printTaskHelp prints what running a task involves: its description, the
+// deps it runs first, the inputs it prompts for and its commands
+func printTaskHelp(taskName string) {
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(ui.Stdout, "❌ Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+
+	task, exists := config.Tasks[taskName]
+	if !exists {
+		fmt.Fprintf(ui.Stdout, "❌ Task %s not found\n", taskName)
+		if suggestions := runner.SuggestTasks(config, taskName); len(suggestions) > 0 {
+			fmt.Fprintf(ui.Stdout, "\n💡 Did you mean: %s?\n", strings.Join(suggestions, ", "))
+		}
+		fmt.Fprintln(ui.Stdout, "\n💡 Use 't :list' to see available tasks")
+		os.Exit(1)
+	}
+
+	if task.Desc != "" {
+		fmt.Fprintf(ui.Stdout, "🔧 %s - %s\n", taskName, task.Desc)
+	} else {
+		fmt.Fprintf(ui.Stdout, "🔧 %s\n", taskName)
+	}
+	fmt.Fprintf(ui.Stdout, "\nUsage:\n  t [flags] %s\n", taskName)
+
+	if len(task.Deps) > 0 {
+		fmt.Fprintf(ui.Stdout, "\n🔗 Runs first: %v\n", task.Deps)
+		if indirect := indirectDeps(config, task); len(indirect) > 0 {
+			fmt.Fprintf(ui.Stdout, "   and their deps: %s\n", strings.Join(indirect, ", "))
+		}
+	}
+	if task.When != "" {
+		fmt.Fprintf(ui.Stdout, "\n⏭️  Only runs when: %s\n", task.When)
+	}
+	if task.Confirm.Enabled {
+		fmt.Fprintln(ui.Stdout, "\n⚠️  Asks for confirmation before running, --yes answers it")
+	}
+
+	printInteractiveInputs(task)
+	printCommands("Before", task.Before)
+	printCommands("Commands", task.Cmds)
+	printCommands("After", task.After)
+
+	fmt.Fprintf(ui.Stdout, "\n💡 Use 't --help' for the flags, 't :describe %s' for its variables\n", taskName)
+}
+
+// indirectDeps returns the tasks a task's deps depend on in turn, in the
+// order they are first reached, without the direct deps
+func indirectDeps(config *runner.Config, task runner.Task) []string {
+	seen := make(map[string]bool)
+	for _, name := range task.Deps.Names() {
+		seen[name] = true
+	}
+
+	var indirect []string
+	var walk func(names []string)
+	walk = func(names []string) {
+		for _, name := range names {
+			for _, dep := range config.Tasks[name].Deps.Names() {
+				if seen[dep] {
+					continue
+				}
+				seen[dep] = true
+				indirect = append(indirect, dep)
+				walk([]string{dep})
+			}
+		}
+	}
+	walk(task.Deps.Names())
+	return indirect
+}
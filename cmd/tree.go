@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"t/internal/runner"
+	"t/internal/ui"
+
+	"github.com/spf13/cobra"
+)
+
+var treeCmd = &cobra.Command{
+	Use:   ":tree",
+	Short: "Show tasks as a tree of their deps",
+	Long:  "Show every task with the tasks it depends on below it, marking the tasks running in the background with their PIDs and uptime. Tasks no other task depends on are the roots. Internal tasks only show up as deps unless --all is given.",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		showAll, _ := cmd.Flags().GetBool("all")
+		asJSON, _ := cmd.Flags().GetBool("json")
+
+		config, err := loadConfig()
+		if err != nil {
+			fmt.Fprintf(ui.Stdout, "❌ Error loading config: %v\n", err)
+			fmt.Fprintln(ui.Stdout, "\n💡 Tip: Run 't :init' to create a tasks.yaml file")
+			os.Exit(1)
+		}
+
+		processes, err := newRunner(config).ListDetachedProcesses()
+		if err != nil {
+			fmt.Fprintf(ui.Stdout, "❌ Error listing detached processes: %v\n", err)
+			os.Exit(1)
+		}
+
+		tree := buildTaskTree(config, processes, showAll)
+		if asJSON {
+			data, err := json.MarshalIndent(tree, "", "  ")
+			if err != nil {
+				fmt.Fprintf(ui.Stdout, "❌ Error encoding tree: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Println(string(data))
+			return
+		}
+
+		if len(tree) == 0 {
+			fmt.Fprintln(ui.Stdout, "No tasks found in tasks.yaml")
+			return
+		}
+		for _, node := range tree {
+			fmt.Fprintf(ui.Stdout, "%s %s\n", node.marker(), node.label())
+			printTreeDeps(node.Deps, "   ")
+		}
+		if len(processes) > 0 {
+			fmt.Fprintln(ui.Stdout)
+			fmt.Fprintf(ui.Stdout, "🚀 %d task(s) running in the background, see 't :ps' for details\n", len(processes))
+		}
+	},
+}
+
+func init() {
+	treeCmd.Flags().BoolP("all", "a", false, "Include internal tasks nothing depends on")
+	treeCmd.Flags().Bool("json", false, "Print the tree as JSON")
+}
+
+// treeNode is a task in the :tree view. A task shown before has its deps
+// left out and Repeated set, which also stops dependency cycles.
+type treeNode struct {
+	Name         string                    `json:"name"`
+	Desc         string                    `json:"desc,omitempty"`
+	LongRunning  bool                      `json:"long_running,omitempty"`
+	AllowFailure bool                      `json:"allow_failure,omitempty"`
+	Always       bool                      `json:"always,omitempty"`
+	Missing      bool                      `json:"missing,omitempty"`
+	Repeated     bool                      `json:"repeated,omitempty"`
+	Processes    []*runner.DetachedProcess `json:"processes,omitempty"`
+	Deps         []*treeNode               `json:"deps,omitempty"`
+}
+
+// buildTaskTree returns the root tasks, those no other task depends on, with
+// their deps below them and their detached processes attached
+func buildTaskTree(config *runner.Config, processes []*runner.DetachedProcess, showAll bool) []*treeNode {
+	byTask := make(map[string][]*runner.DetachedProcess)
+	for _, proc := range processes {
+		byTask[proc.TaskName] = append(byTask[proc.TaskName], proc)
+	}
+
+	isDep := make(map[string]bool)
+	for _, task := range config.Tasks {
+		for _, dep := range task.Deps.Names() {
+			isDep[dep] = true
+		}
+	}
+
+	var roots []string
+	for taskName, task := range config.Tasks {
+		if isDep[taskName] || (!showAll && runner.IsInternalTask(taskName, task)) {
+			continue
+		}
+		roots = append(roots, taskName)
+	}
+	sort.Strings(roots)
+
+	shown := make(map[string]bool)
+	var build func(dep runner.Dep) *treeNode
+	build = func(dep runner.Dep) *treeNode {
+		task, exists := config.Tasks[dep.Task]
+		node := &treeNode{
+			Name:         dep.Task,
+			Desc:         task.Desc,
+			LongRunning:  task.LongRunning,
+			AllowFailure: dep.AllowFailure,
+			Always:       dep.Always,
+			Missing:      !exists,
+			Processes:    byTask[dep.Task],
+		}
+		if shown[dep.Task] {
+			node.Repeated = len(task.Deps) > 0
+			return node
+		}
+		shown[dep.Task] = true
+		for _, stage := range task.Deps {
+			for _, child := range stage {
+				child.Always = child.Always || task.DepsAlways
+				node.Deps = append(node.Deps, build(child))
+			}
+		}
+		return node
+	}
+
+	tree := make([]*treeNode, 0, len(roots))
+	for _, root := range roots {
+		tree = append(tree, build(runner.Dep{Task: root}))
+	}
+
+	// Tasks that only depend on each other in a cycle have no root
+	var rest []string
+	for taskName, task := range config.Tasks {
+		if !shown[taskName] && (showAll || !runner.IsInternalTask(taskName, task)) {
+			rest = append(rest, taskName)
+		}
+	}
+	sort.Strings(rest)
+	for _, taskName := range rest {
+		if !shown[taskName] {
+			tree = append(tree, build(runner.Dep{Task: taskName}))
+		}
+	}
+	return tree
+}
+
+// printTreeDeps prints deps below their task with branch lines
+func printTreeDeps(deps []*treeNode, indent string) {
+	for i, dep := range deps {
+		branch, next := "├── ", "│   "
+		if i == len(deps)-1 {
+			branch, next = "└── ", "    "
+		}
+		fmt.Fprintf(ui.Stdout, "%s%s%s\n", indent, branch, dep.label())
+		printTreeDeps(dep.Deps, indent+next)
+	}
+}
+
+// marker returns the marker of a root task, like in :list
+func (n *treeNode) marker() string {
+	if n.LongRunning {
+		return "🚀"
+	}
+	return "🔧"
+}
+
+// label describes a task on its tree line: name, description and state
+func (n *treeNode) label() string {
+	var b strings.Builder
+	b.WriteString(n.Name)
+	if n.Desc != "" {
+		fmt.Fprintf(&b, " - %s", n.Desc)
+	}
+	if n.AllowFailure {
+		b.WriteString(" (may fail)")
+	}
+	if n.Always {
+		b.WriteString(" (always)")
+	}
+	if n.Missing {
+		b.WriteString(" (no such task)")
+	}
+	if n.Repeated {
+		b.WriteString(" (deps shown above)")
+	}
+	for _, proc := range n.Processes {
+		fmt.Fprintf(&b, " [running: PID %d, up %v]", proc.PID, time.Since(proc.StartedAt).Round(time.Second))
+	}
+	return b.String()
+}
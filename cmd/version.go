@@ -1,7 +1,17 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"t/internal/ui"
 
 	"github.com/spf13/cobra"
 )
@@ -18,9 +28,143 @@ var versionCmd = &cobra.Command{
 	Short: "Print the version information",
 	Long:  "Display version and build information for the t task runner.",
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Printf("t task runner\n")
-		fmt.Printf("Version: %s\n", Version)
-		fmt.Printf("Built: %s\n", BuildDate)
-		fmt.Printf("Author: Mohamed Eid\n")
+		fmt.Fprintf(ui.Stdout, "t task runner\n")
+		fmt.Fprintf(ui.Stdout, "Version: %s\n", Version)
+		fmt.Fprintf(ui.Stdout, "Built: %s\n", BuildDate)
+		fmt.Fprintf(ui.Stdout, "Author: Mohamed Eid\n")
+
+		if check, _ := cmd.Flags().GetBool("check"); check {
+			fmt.Fprintln(ui.Stdout)
+			checkLatestVersion()
+		}
 	},
 }
+
+func init() {
+	versionCmd.Flags().Bool("check", false, "Check GitHub for a newer release")
+}
+
+const (
+	releasesURL       = "https://github.com/Mohamed-Eid/t/releases/latest"
+	latestReleaseAPI  = "https://api.github.com/repos/Mohamed-Eid/t/releases/latest"
+	versionCheckTTL   = 24 * time.Hour
+	versionCheckCache = ".t-version-check.json"
+)
+
+// versionCheck is the cached result of the latest release lookup
+type versionCheck struct {
+	Latest    string    `json:"latest"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// checkLatestVersion reports whether a newer release than Version exists
+func checkLatestVersion() {
+	latest, err := latestVersion()
+	if err != nil {
+		fmt.Fprintf(ui.Stdout, "⚠️  Could not check for updates: %v\n", err)
+		return
+	}
+
+	switch {
+	case Version == "dev":
+		fmt.Fprintf(ui.Stdout, "💡 Latest release is %s (this is a development build)\n", latest)
+	case compareVersions(Version, latest) < 0:
+		fmt.Fprintf(ui.Stdout, "🆕 A newer version is available: %s (you have %s)\n", latest, Version)
+		archive := fmt.Sprintf("t-%s-%s.tar.gz", runtime.GOOS, runtime.GOARCH)
+		if runtime.GOOS == "windows" {
+			archive = fmt.Sprintf("t-%s-%s.zip", runtime.GOOS, runtime.GOARCH)
+		}
+		fmt.Fprintf(ui.Stdout, "💡 Download %s from %s\n", archive, releasesURL)
+	default:
+		fmt.Fprintf(ui.Stdout, "✅ You are running the latest version (%s)\n", Version)
+	}
+}
+
+// latestVersion returns the latest release tag, from a cache in the home
+// directory when it was checked within the last day
+func latestVersion() (string, error) {
+	cachePath := ""
+	if home, err := os.UserHomeDir(); err == nil {
+		cachePath = filepath.Join(home, versionCheckCache)
+	}
+
+	if cachePath != "" {
+		var cached versionCheck
+		if data, err := os.ReadFile(cachePath); err == nil && json.Unmarshal(data, &cached) == nil {
+			if cached.Latest != "" && time.Since(cached.CheckedAt) < versionCheckTTL {
+				return cached.Latest, nil
+			}
+		}
+	}
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	req, err := http.NewRequest(http.MethodGet, latestReleaseAPI, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("request failed (offline?): %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("GitHub returned %s", resp.Status)
+	}
+
+	var release struct {
+		TagName string `json:"tag_name"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return "", err
+	}
+	if release.TagName == "" {
+		return "", fmt.Errorf("no release tag found")
+	}
+
+	if cachePath != "" {
+		if data, err := json.Marshal(versionCheck{Latest: release.TagName, CheckedAt: time.Now()}); err == nil {
+			os.WriteFile(cachePath, data, 0644)
+		}
+	}
+
+	return release.TagName, nil
+}
+
+// compareVersions compares dotted versions like v1.2.3 numerically and
+// returns -1, 0 or 1. Pre-release suffixes are ignored.
+func compareVersions(a, b string) int {
+	partsA := versionParts(a)
+	partsB := versionParts(b)
+	for i := 0; i < len(partsA) || i < len(partsB); i++ {
+		var x, y int
+		if i < len(partsA) {
+			x = partsA[i]
+		}
+		if i < len(partsB) {
+			y = partsB[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// versionParts splits "v1.2.3-rc1" into [1 2 3]
+func versionParts(version string) []int {
+	version = strings.TrimPrefix(version, "v")
+	version, _, _ = strings.Cut(version, "-")
+
+	var parts []int
+	for _, field := range strings.Split(version, ".") {
+		n, _ := strconv.Atoi(field)
+		parts = append(parts, n)
+	}
+	return parts
+}
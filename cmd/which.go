@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"t/internal/runner"
+	"t/internal/ui"
+
+	"github.com/spf13/cobra"
+)
+
+var whichCmd = &cobra.Command{
+	Use:   ":which <task-name>",
+	Short: "Show which config file and line define a task",
+	Long:  "Print the absolute path and line of the config file that defines the task that would run, taking the user-global config into account.",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		taskName := args[0]
+
+		config, err := loadConfig()
+		if err != nil {
+			fmt.Fprintf(ui.Stdout, "❌ Error loading config: %v\n", err)
+			fmt.Fprintln(ui.Stdout, "\n💡 Tip: Run 't :init' to create a tasks.yaml file")
+			os.Exit(1)
+		}
+
+		task, exists := config.Tasks[taskName]
+		if !exists {
+			fmt.Fprintf(ui.Stdout, "❌ Task %s not found\n", taskName)
+			if suggestions := runner.SuggestTasks(config, taskName); len(suggestions) > 0 {
+				fmt.Fprintf(ui.Stdout, "\n💡 Did you mean: %s?\n", strings.Join(suggestions, ", "))
+			}
+			os.Exit(1)
+		}
+
+		source, origin := task.Source, "included file"
+		switch {
+		case source == "":
+			source, origin = configSource(), "project config"
+		case task.Global && source == globalConfigPath():
+			origin = "global config"
+		case task.Global:
+			origin = "included by the global config"
+		}
+		switch {
+		case source == "-":
+			source = "stdin"
+		case strings.Contains(source, "://"):
+			// Remote configs are shown by URL
+		default:
+			if abs, err := filepath.Abs(source); err == nil {
+				source = abs
+			}
+		}
+
+		fmt.Fprintf(ui.Stdout, "📄 %s:%d (%s)\n", source, task.Line, origin)
+	},
+}
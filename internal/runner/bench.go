@@ -0,0 +1,76 @@
+package runner
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// BenchStats summarizes the durations of the timed runs of BenchTask
+type BenchStats struct {
+	Min    time.Duration
+	Median time.Duration
+	Mean   time.Duration
+	Max    time.Duration
+	Stddev time.Duration
+}
+
+// BenchTask runs a task warmup times, then runs times more, and returns how
+// long each of the timed runs took. Every run starts with an empty Ran map
+// so the task and its dependencies run again. It stops at the first failure.
+func (r *Runner) BenchTask(taskName string, runs, warmup int) ([]time.Duration, error) {
+	for i := 1; i <= warmup; i++ {
+		fmt.Fprintf(r.Out, "⏱️  Warmup %d/%d\n", i, warmup)
+		if err := r.iterationRunner().RunTask(taskName); err != nil {
+			return nil, fmt.Errorf("warmup %d: %w", i, err)
+		}
+	}
+
+	samples := make([]time.Duration, 0, runs)
+	for i := 1; i <= runs; i++ {
+		fmt.Fprintf(r.Out, "⏱️  Run %d/%d\n", i, runs)
+		start := time.Now()
+		if err := r.iterationRunner().RunTask(taskName); err != nil {
+			return samples, fmt.Errorf("run %d: %w", i, err)
+		}
+		samples = append(samples, time.Since(start))
+	}
+	return samples, nil
+}
+
+// NewBenchStats computes the statistics of a non-empty set of durations. The
+// standard deviation is the sample one, zero for a single duration.
+func NewBenchStats(samples []time.Duration) BenchStats {
+	sorted := append([]time.Duration(nil), samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var sum time.Duration
+	for _, sample := range sorted {
+		sum += sample
+	}
+	mean := sum / time.Duration(len(sorted))
+
+	median := sorted[len(sorted)/2]
+	if len(sorted)%2 == 0 {
+		median = (sorted[len(sorted)/2-1] + median) / 2
+	}
+
+	var stddev time.Duration
+	if len(sorted) > 1 {
+		var squares float64
+		for _, sample := range sorted {
+			diff := float64(sample - mean)
+			squares += diff * diff
+		}
+		stddev = time.Duration(math.Sqrt(squares / float64(len(sorted)-1)))
+	}
+
+	return BenchStats{
+		Min:    sorted[0],
+		Median: median,
+		Mean:   mean,
+		Max:    sorted[len(sorted)-1],
+		Stddev: stddev,
+	}
+}
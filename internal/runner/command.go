@@ -0,0 +1,282 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"reflect"
+	"runtime"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Command represents a single entry in a task's cmds list. It can be written
+// either as a plain string or as an object with extra options, or reference
+// another task to run inline at that position. An exec list runs a program
+// directly instead of through the shell.
+type Command struct {
+	Cmd       string   `yaml:"cmd,omitempty"`
+	Exec      []string `yaml:"exec,omitempty"`
+	Task      string   `yaml:"task,omitempty"`
+	Echo      *bool    `yaml:"echo,omitempty"`
+	Prefix    string   `yaml:"prefix,omitempty"`
+	Timeout   string   `yaml:"timeout,omitempty"`
+	Stdin     string   `yaml:"stdin,omitempty"`
+	StdinFile string   `yaml:"stdin_file,omitempty"`
+	Capture   string   `yaml:"capture,omitempty"`
+	Dir       string   `yaml:"dir,omitempty"`
+	// Source names the config file the command came from when it isn't the
+	// project config, and SourceLine is the line it is written on
+	Source     string `yaml:"-"`
+	SourceLine int    `yaml:"-"`
+}
+
+// UnmarshalYAML accepts both the plain string form and the object form
+func (c *Command) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*c = Command{Cmd: value.Value, SourceLine: value.Line}
+		return nil
+	}
+
+	// Decode into an alias type to avoid recursing into this method
+	type rawCommand Command
+	var raw rawCommand
+	if err := value.Decode(&raw); err != nil {
+		return err
+	}
+
+	*c = Command(raw)
+	c.SourceLine = value.Line
+	return nil
+}
+
+// Line returns the command as written, with exec arguments quoted like a
+// shell would need them
+func (c Command) Line() string {
+	if len(c.Exec) == 0 {
+		return c.Cmd
+	}
+	return joinArgs(c.Exec)
+}
+
+// MarshalYAML writes a command that only has a cmd in the plain string form
+func (c Command) MarshalYAML() (any, error) {
+	// Where the command came from doesn't change how it is written
+	c.Source, c.SourceLine = "", 0
+	if reflect.DeepEqual(c, Command{Cmd: c.Cmd}) {
+		return c.Cmd, nil
+	}
+
+	type rawCommand Command
+	return rawCommand(c), nil
+}
+
+// ShouldEcho reports whether the command line is printed before it runs
+func (c Command) ShouldEcho() bool {
+	return c.Echo == nil || *c.Echo
+}
+
+// commandStdin returns the reader for a command's stdin: its stdin text or
+// the opened stdin_file, both after variable expansion, or nil when neither
+// is set. The caller closes the reader when it is an io.Closer.
+func (r *Runner) commandStdin(command Command, vars map[string]string, interactiveInputs map[string]string) (io.Reader, error) {
+	if command.Stdin != "" && command.StdinFile != "" {
+		return nil, fmt.Errorf("command %q sets both stdin and stdin_file", command.Line())
+	}
+
+	if command.Stdin != "" {
+		text, err := r.expandVars(command.Stdin, vars)
+		if err != nil {
+			return nil, err
+		}
+		text, err = r.expandVarsWithInteractive(text, interactiveInputs)
+		if err != nil {
+			return nil, err
+		}
+		return strings.NewReader(text), nil
+	}
+
+	if command.StdinFile != "" {
+		filename, err := r.expandVars(command.StdinFile, vars)
+		if err != nil {
+			return nil, err
+		}
+		file, err := os.Open(filename)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open stdin_file: %w", err)
+		}
+		return file, nil
+	}
+
+	return nil, nil
+}
+
+// newCommand expands a command of task and returns the process that runs it
+// along with the line to show for it, with secrets masked. A cmd string runs
+// through the shell, with the answers of prompts marked quote shell-quoted.
+// Each exec argument is expanded on its own and passed to the program as
+// is, so values with spaces or quotes never need quoting. A command with a
+// dir runs there.
+func (r *Runner) newCommand(ctx context.Context, task Task, command Command, vars map[string]string, interactiveInputs map[string]string) (*exec.Cmd, string, error) {
+	dir, err := r.expandVars(command.Dir, vars)
+	if err != nil {
+		return nil, "", fmt.Errorf("dir: %w", err)
+	}
+	if dir != "" {
+		dir = r.resolvePath(dir)
+	}
+
+	if len(command.Exec) == 0 {
+		cmdStr, err := r.expandVars(command.Cmd, vars)
+		if err != nil {
+			return nil, "", err
+		}
+		cmdStr, err = r.expandVarsWithInteractive(cmdStr, task.shellInputs(interactiveInputs))
+		if err != nil {
+			return nil, "", err
+		}
+		cmd := r.shellCommand(ctx, cmdStr)
+		cmd.Dir = dir
+		return cmd, MaskSecrets(cmdStr), nil
+	}
+
+	if command.Cmd != "" {
+		return nil, "", fmt.Errorf("command %q sets both cmd and exec", command.Cmd)
+	}
+	args := make([]string, len(command.Exec))
+	for i, arg := range command.Exec {
+		expanded, err := r.expandVars(arg, vars)
+		if err != nil {
+			return nil, "", err
+		}
+		args[i], err = r.expandVarsWithInteractive(expanded, interactiveInputs)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Dir = dir
+	return cmd, MaskSecrets(joinArgs(args)), nil
+}
+
+// joinArgs joins arguments into a line, quoting the ones a shell would split
+func joinArgs(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = shellQuote(arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// shellQuote wraps a value in single quotes unless it only holds characters
+// that are safe in a POSIX shell
+func shellQuote(value string) string {
+	if value != "" && strings.Trim(value, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_@%+=:,./-") == "" {
+		return value
+	}
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// shellInputs returns the interactive inputs to substitute into a cmd
+// string, quoting the answers of prompts marked quote
+func (t Task) shellInputs(interactiveInputs map[string]string) map[string]string {
+	inputs := make(map[string]string, len(interactiveInputs))
+	for name, value := range interactiveInputs {
+		if t.Interactive[name].Quote {
+			value = quoteInput(value)
+		}
+		inputs[name] = value
+	}
+	return inputs
+}
+
+// quoteInput quotes a value as a single argument for the shell that runs
+// cmd strings: sh, or PowerShell on Windows
+func quoteInput(value string) string {
+	if runtime.GOOS == "windows" {
+		return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+	}
+	return shellQuote(value)
+}
+
+// closeStdin closes a reader returned by commandStdin
+func closeStdin(reader io.Reader) {
+	if closer, ok := reader.(io.Closer); ok {
+		closer.Close()
+	}
+}
+
+// checkCommandFields reports keys of command objects in the config that
+// don't match any Command field
+func (t *configTree) checkCommandFields() error {
+	if t.root == nil {
+		return nil
+	}
+
+	known := make(map[string]bool)
+	commandType := reflect.TypeOf(Command{})
+	for i := 0; i < commandType.NumField(); i++ {
+		name, _, _ := strings.Cut(commandType.Field(i).Tag.Get("yaml"), ",")
+		known[name] = true
+	}
+
+	for _, list := range []string{"before_task", "after_task"} {
+		if err := t.checkCommandList(mappingValue(t.root, list), known, list); err != nil {
+			return err
+		}
+	}
+
+	tasks := mappingValue(t.root, "tasks")
+	if tasks == nil || tasks.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	for i := 1; i < len(tasks.Content); i += 2 {
+		owner := "task " + tasks.Content[i-1].Value
+		for _, list := range []string{"cmds", "before", "after"} {
+			if err := t.checkCommandList(mappingValue(tasks.Content[i], list), known, owner); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkCommandList reports the first unknown key in the command objects of a
+// command list node. owner names the list in the error.
+func (t *configTree) checkCommandList(cmds *yaml.Node, known map[string]bool, owner string) error {
+	if cmds == nil || cmds.Kind != yaml.SequenceNode {
+		return nil
+	}
+
+	for _, item := range cmds.Content {
+		if item.Kind != yaml.MappingNode {
+			continue
+		}
+		for j := 0; j < len(item.Content); j += 2 {
+			key := item.Content[j]
+			if !known[key.Value] {
+				return t.errorAt(key, fmt.Errorf("line %d: field %s not found in command of %s", key.Line, key.Value, owner))
+			}
+		}
+	}
+
+	return nil
+}
+
+// mappingValue returns the value node for key in a YAML mapping node
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
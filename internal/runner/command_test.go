@@ -0,0 +1,112 @@
+package runner
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// hostileInput tries to end the command, run others through command
+// substitution and break out of quotes
+const hostileInput = `x; touch pwned1 $(touch pwned2) ` + "`touch pwned3`" + ` 'q' "dq" \`
+
+func TestQuoteNeutralizesInteractiveInput(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("cmd strings run through PowerShell on Windows")
+	}
+
+	dir := t.TempDir()
+	r := testRunner(t, `
+tasks:
+  greet:
+    interactive:
+      name:
+        message: Name
+        quote: true
+    cmds:
+      - printf '%s\n' $name
+`)
+	r.Dir = dir
+	var out bytes.Buffer
+	r.CommandOut = &out
+	r.input = bufio.NewReader(strings.NewReader(hostileInput + "\n"))
+
+	if err := r.RunTask("greet"); err != nil {
+		t.Fatalf("RunTask: %v", err)
+	}
+	if got := strings.TrimSuffix(out.String(), "\n"); got != hostileInput {
+		t.Errorf("command printed %q, want the input as a single argument %q", got, hostileInput)
+	}
+	for _, name := range []string{"pwned1", "pwned2", "pwned3"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			t.Errorf("input ran a command that created %s", name)
+		}
+	}
+}
+
+func TestShellInputsQuotesOnlyMarkedPrompts(t *testing.T) {
+	task := Task{Interactive: map[string]Prompt{
+		"quoted": {Quote: true},
+		"raw":    {},
+	}}
+	inputs := task.shellInputs(map[string]string{"quoted": "a;b", "raw": "a;b"})
+
+	if inputs["raw"] != "a;b" {
+		t.Errorf("raw input = %q, want it unchanged", inputs["raw"])
+	}
+	if runtime.GOOS != "windows" && inputs["quoted"] != "'a;b'" {
+		t.Errorf("quoted input = %q, want 'a;b'", inputs["quoted"])
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	tests := map[string]string{
+		"plain":      "plain",
+		"":           "''",
+		"two words":  "'two words'",
+		"it's":       `'it'\''s'`,
+		"$(rm -rf)":  "'$(rm -rf)'",
+		"a;b":        "'a;b'",
+		`"double"`:   `'"double"'`,
+		"path/to.go": "path/to.go",
+	}
+	for value, want := range tests {
+		if got := shellQuote(value); got != want {
+			t.Errorf("shellQuote(%q) = %q, want %q", value, got, want)
+		}
+	}
+}
+
+func TestCommandDirResolvesAgainstRunnerDir(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("cmd strings run through PowerShell on Windows")
+	}
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	r := testRunner(t, `
+tasks:
+  where:
+    cmds: ["pwd", {cmd: pwd, dir: sub}]
+`)
+	// The process stays in the test's directory, below which sub doesn't exist
+	r.Dir = root
+	var out bytes.Buffer
+	r.CommandOut = &out
+	if err := r.RunTask("where"); err != nil {
+		t.Fatal(err)
+	}
+	want := root + "\n" + filepath.Join(root, "sub") + "\n"
+	if out.String() != want {
+		t.Errorf("commands ran in:\n%swant:\n%s", out.String(), want)
+	}
+}
@@ -0,0 +1,173 @@
+package runner
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Dep is a dependency of a task. Written as a task name, or as
+// {task: lint, allow_failure: true} for a dependency whose failure only
+// warns instead of failing the task needing it. A dep marked always runs
+// for the task needing it even if it already ran in this invocation.
+type Dep struct {
+	Task         string `yaml:"task"`
+	AllowFailure bool   `yaml:"allow_failure,omitempty"`
+	Always       bool   `yaml:"always,omitempty"`
+}
+
+// UnmarshalYAML accepts both the name and the object form
+func (d *Dep) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind != yaml.MappingNode {
+		return value.Decode(&d.Task)
+	}
+
+	// Custom unmarshalers aren't checked for unknown keys, so check here
+	if StrictConfig {
+		for i := 0; i < len(value.Content); i += 2 {
+			if key := value.Content[i]; key.Value != "task" && key.Value != "allow_failure" && key.Value != "always" {
+				return fmt.Errorf("line %d: field %s not found in dep", key.Line, key.Value)
+			}
+		}
+	}
+
+	type rawDep Dep
+	if err := value.Decode((*rawDep)(d)); err != nil {
+		return err
+	}
+	if d.Task == "" {
+		return fmt.Errorf("line %d: dep has no task", value.Line)
+	}
+	return nil
+}
+
+// MarshalYAML writes a dep without options as its task name
+func (d Dep) MarshalYAML() (any, error) {
+	if !d.AllowFailure && !d.Always {
+		return d.Task, nil
+	}
+
+	type rawDep Dep
+	return rawDep(d), nil
+}
+
+// String returns the task name, marked when the dep may fail or always runs
+func (d Dep) String() string {
+	var marks []string
+	if d.AllowFailure {
+		marks = append(marks, "may fail")
+	}
+	if d.Always {
+		marks = append(marks, "always")
+	}
+	if len(marks) == 0 {
+		return d.Task
+	}
+	return d.Task + " (" + strings.Join(marks, ", ") + ")"
+}
+
+// Deps holds a task's dependencies as stages. Stages run one after another
+// and the deps of a stage run in parallel. A flat list such as [a, b] is a
+// single stage, while a nested list such as [[a, b], c] makes every entry
+// its own stage: a and b run together, then c.
+type Deps [][]Dep
+
+// UnmarshalYAML accepts both the flat and the nested list form
+func (d *Deps) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind != yaml.SequenceNode {
+		return fmt.Errorf("line %d: deps must be a list of tasks, such as [build] or [[lint, test], build]", value.Line)
+	}
+
+	nested := false
+	for _, item := range value.Content {
+		if item.Kind == yaml.SequenceNode {
+			nested = true
+			break
+		}
+	}
+
+	if !nested {
+		var stage []Dep
+		if err := value.Decode(&stage); err != nil {
+			return err
+		}
+		*d = nil
+		if len(stage) > 0 {
+			*d = Deps{stage}
+		}
+		return nil
+	}
+
+	stages := make(Deps, 0, len(value.Content))
+	for _, item := range value.Content {
+		var stage []Dep
+		if item.Kind == yaml.SequenceNode {
+			if err := item.Decode(&stage); err != nil {
+				return err
+			}
+		} else {
+			var dep Dep
+			if err := item.Decode(&dep); err != nil {
+				return err
+			}
+			stage = []Dep{dep}
+		}
+		if len(stage) > 0 {
+			stages = append(stages, stage)
+		}
+	}
+	*d = stages
+	return nil
+}
+
+// MarshalYAML writes a single stage as a flat list
+func (d Deps) MarshalYAML() (any, error) {
+	if len(d) == 1 {
+		return d[0], nil
+	}
+	return [][]Dep(d), nil
+}
+
+// Names returns every dependency in the order they are written
+func (d Deps) Names() []string {
+	var names []string
+	for _, stage := range d {
+		for _, dep := range stage {
+			names = append(names, dep.Task)
+		}
+	}
+	return names
+}
+
+// String formats the dependencies like [a b], with stages separated by
+// arrows: [a b] → [c]
+func (d Deps) String() string {
+	stages := make([]string, len(d))
+	for i, stage := range d {
+		stages[i] = fmt.Sprint(stage)
+	}
+	return strings.Join(stages, " → ")
+}
+
+// runDependencies runs the dependency stages in order, stopping at the first
+// stage that fails
+func (r *Runner) runDependencies(stages [][]Dep, path []string) error {
+	for _, stage := range stages {
+		if err := r.runDependenciesParallel(stage, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// allowFailure turns the failure of a dep marked allow_failure into a
+// warning, so the task needing it still runs. The failure stays in the
+// dep's own results.
+func (r *Runner) allowFailure(dep Dep, err error, path []string) error {
+	if err == nil || !dep.AllowFailure {
+		return err
+	}
+	fmt.Fprintf(r.pathOut(path), "⚠️  Dependency %s failed, continuing since it allows failure: %v\n", dep.Task, err)
+	return nil
+}
@@ -0,0 +1,82 @@
+package runner
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+// testRunner loads a config from YAML and returns a runner for it that
+// prints nothing
+func testRunner(t *testing.T, config string) *Runner {
+	t.Helper()
+	loaded, err := LoadConfigReader(strings.NewReader(config))
+	if err != nil {
+		t.Fatalf("loading config: %v", err)
+	}
+	r := NewRunner(loaded)
+	r.Out = io.Discard
+	r.ErrOut = io.Discard
+	return r
+}
+
+func TestTemplatedDepResolves(t *testing.T) {
+	r := testRunner(t, `
+vars:
+  TARGET: linux
+tasks:
+  build-linux:
+    cmds: ["echo linux"]
+  build-darwin:
+    cmds: ["echo darwin"]
+  release:
+    deps: ["build-{{.TARGET}}"]
+    cmds: ["echo release"]
+`)
+
+	if err := r.RunTask("release"); err != nil {
+		t.Fatalf("RunTask: %v", err)
+	}
+	if !r.Ran["build-linux"] {
+		t.Error("build-linux didn't run")
+	}
+	if r.Ran["build-darwin"] {
+		t.Error("build-darwin ran although the dep doesn't name it")
+	}
+}
+
+func TestTemplatedDepExpandsToSeveralOrNone(t *testing.T) {
+	r := testRunner(t, `
+vars:
+  CHECKS: "lint, vet"
+tasks:
+  lint:
+    cmds: ["echo lint"]
+  vet:
+    cmds: ["echo vet"]
+  ci:
+    deps: ["{{.CHECKS}}", "{{.EXTRA}}"]
+    cmds: ["echo ci"]
+`)
+
+	stages, err := r.expandDeps(r.Config.Tasks["ci"], map[string]string{"CHECKS": "lint, vet"})
+	if err != nil {
+		t.Fatalf("expandDeps: %v", err)
+	}
+	if len(stages) != 1 || len(stages[0]) != 2 || stages[0][0].Task != "lint" || stages[0][1].Task != "vet" {
+		t.Fatalf("expandDeps = %v, want one stage of lint and vet", stages)
+	}
+}
+
+func TestDepsMustBeAList(t *testing.T) {
+	_, err := LoadConfigReader(strings.NewReader(`
+tasks:
+  build:
+    cmds: ["echo build"]
+  release:
+    deps: build
+`))
+	if err == nil || !strings.Contains(err.Error(), "line 6: deps must be a list") {
+		t.Fatalf("got %v, want an error naming the line", err)
+	}
+}
@@ -0,0 +1,38 @@
+package runner
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDetachedTaskSkippedWhenConditionFails(t *testing.T) {
+	r := testRunner(t, `
+tasks:
+  serve:
+    when: "false"
+    cmds: ["sleep 30"]
+`)
+	r.StateDir = t.TempDir()
+
+	proc, err := r.RunTaskDetached("serve")
+	if err != nil || proc != nil {
+		t.Fatalf("got %v, %v, want the task skipped", proc, err)
+	}
+	if processes, _ := r.ListDetachedProcesses(); len(processes) > 0 {
+		t.Errorf("a skipped task was started: %+v", processes[0])
+	}
+}
+
+func TestDetachedMatrixTaskFails(t *testing.T) {
+	r := testRunner(t, `
+tasks:
+  serve:
+    matrix: {port: ["80", "81"]}
+    cmds: ["sleep 30"]
+`)
+	r.StateDir = t.TempDir()
+
+	if _, err := r.RunTaskDetached("serve"); err == nil || !strings.Contains(err.Error(), "matrix") {
+		t.Errorf("got %v, want a matrix error", err)
+	}
+}
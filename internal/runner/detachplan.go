@@ -0,0 +1,113 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// planDetached prints what RunTaskDetached would do for a task: the deps and
+// setup commands run first in the foreground, the main command started in
+// the background and how it is set up. Nothing is run and no file is
+// written. The returned process has PID 0.
+func (r *Runner) planDetached(taskName string, task Task, vars map[string]string) (*DetachedProcess, error) {
+	deps, err := r.expandDeps(task, vars)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expand deps of task %s: %w", taskName, err)
+	}
+	if len(task.Cmds) == 0 {
+		return nil, fmt.Errorf("task %s has no commands to run", taskName)
+	}
+	mainCmd := task.Cmds[len(task.Cmds)-1]
+	if mainCmd.Task != "" {
+		return nil, fmt.Errorf("the last command of detached task %s must be a shell command, not a task reference", taskName)
+	}
+
+	// Expanding without running catches broken templates now
+	var steps []string
+	for _, command := range task.Cmds[:len(task.Cmds)-1] {
+		if command.Task != "" {
+			name, err := r.expandVars(command.Task, vars)
+			if err != nil {
+				return nil, err
+			}
+			steps = append(steps, "task: "+name)
+			continue
+		}
+		_, cmdStr, err := r.newCommand(context.Background(), task, command, vars, nil)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, cmdStr)
+	}
+	_, cmdStr, err := r.newCommand(context.Background(), task, mainCmd, vars, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	stdin := "the null device"
+	switch {
+	case r.DetachedStdin != "":
+		stdin = r.DetachedStdin
+	case mainCmd.StdinFile != "":
+		filename, err := r.expandVars(mainCmd.StdinFile, vars)
+		if err != nil {
+			return nil, err
+		}
+		stdin = filename
+	case mainCmd.Stdin != "":
+		stdin = "the command's stdin text"
+	}
+
+	logFile := r.detachedLogFile(taskName)
+
+	fmt.Fprintf(r.Out, "🧭 Dry run of detached task: %s\n", taskName)
+	if task.Confirm.Enabled && !r.AssumeYes {
+		fmt.Fprintln(r.Out, "   asks for confirmation before starting")
+	}
+	if len(deps) > 0 {
+		fmt.Fprintf(r.Out, "   dependencies (foreground): %s\n", Deps(deps))
+	}
+	if task.When != "" {
+		fmt.Fprintf(r.Out, "   starts only if when holds: %s\n", task.When)
+	}
+	if task.Lock.Enabled {
+		fmt.Fprintf(r.Out, "   takes lock %s before the setup commands\n", task.Lock.LockName(taskName))
+	}
+	var hooks []Command
+	if !task.NoGlobalHooks {
+		hooks = append(hooks, r.Config.BeforeTask...)
+	}
+	if hooks = append(hooks, task.Before...); len(hooks) > 0 {
+		fmt.Fprintln(r.Out, "   before hooks (foreground):")
+		for i, hook := range hooks {
+			fmt.Fprintf(r.Out, "     %d. %s\n", i+1, hook.Line())
+		}
+	}
+	if r.hasAfterHooks(task) {
+		fmt.Fprintln(r.Out, "   after hooks: not run, as the task outlives t")
+	}
+	if len(steps) > 0 {
+		fmt.Fprintln(r.Out, "   setup commands (foreground):")
+		for i, step := range steps {
+			fmt.Fprintf(r.Out, "     %d. %s\n", i+1, step)
+		}
+	}
+	fmt.Fprintf(r.Out, "   main command (background): %s\n", cmdStr)
+	fmt.Fprintf(r.Out, "   log file: %s\n", logFile)
+	if len(r.DetachedLogWriter) > 0 {
+		fmt.Fprintf(r.Out, "   log writer: %s %s\n", joinArgs(r.DetachedLogWriter), logFile)
+	}
+	fmt.Fprintf(r.Out, "   stdin: %s\n", stdin)
+	fmt.Fprintf(r.Out, "   process group: %s\n", processGroupSetup)
+	fmt.Fprintln(r.Out, "🧭 Nothing was started")
+
+	return &DetachedProcess{
+		PID:       0,
+		TaskName:  taskName,
+		Command:   cmdStr,
+		StartedAt: time.Now(),
+		LogFile:   logFile,
+		Steps:     append(steps, cmdStr),
+	}, nil
+}
@@ -0,0 +1,90 @@
+package runner
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// LoadEnvFile parses a dotenv style file of KEY=VALUE lines. Blank lines and
+// lines starting with # are ignored, an optional "export " prefix is allowed,
+// and values may be wrapped in single or double quotes. Double-quoted values
+// support \n, \t, \" and \\ escapes.
+func LoadEnvFile(filename string) (map[string]string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	env := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, found := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !found || key == "" || strings.ContainsAny(key, " \t") {
+			return nil, fmt.Errorf("%s:%d: expected KEY=VALUE", filename, lineNum)
+		}
+
+		env[key] = parseEnvValue(strings.TrimSpace(value))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return env, nil
+}
+
+// parseEnvValue removes quotes from a value and strips trailing comments from
+// unquoted values
+func parseEnvValue(value string) string {
+	if len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'' {
+		return value[1 : len(value)-1]
+	}
+
+	if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+		replacer := strings.NewReplacer(`\n`, "\n", `\t`, "\t", `\"`, `"`, `\\`, `\`)
+		return replacer.Replace(value[1 : len(value)-1])
+	}
+
+	if i := strings.Index(value, " #"); i >= 0 {
+		value = strings.TrimSpace(value[:i])
+	}
+	return value
+}
+
+// setCommandEnv gives cmd its own copy of the environment, with Env layered
+// on top, and runs it in Dir unless it has a dir of its own. Commands of
+// parallel tasks are each set up this way, so none of them shares a slice
+// with another or depends on the process's working directory at the time
+// it starts.
+func (r *Runner) setCommandEnv(cmd *exec.Cmd) {
+	cmd.Env = r.commandEnv()
+	if cmd.Dir == "" {
+		cmd.Dir = r.Dir
+	}
+}
+
+// commandEnv returns a fresh copy of the current environment with Env
+// layered on top
+func (r *Runner) commandEnv() []string {
+	env := os.Environ()
+	keys := make([]string, 0, len(r.Env))
+	for key := range r.Env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		env = append(env, key+"="+r.Env[key])
+	}
+	return env
+}
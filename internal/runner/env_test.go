@@ -0,0 +1,97 @@
+package runner
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// syncBuffer is a buffer that parallel commands can write to at once
+type syncBuffer struct {
+	buf   bytes.Buffer
+	mutex sync.Mutex
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buf.Write(p)
+}
+
+func TestParallelDepsDontShareEnvOrDir(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("cmd strings run through PowerShell on Windows")
+	}
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range []string{"a", "b"} {
+		if err := os.Mkdir(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Both deps set the same variable and change directory at the same
+	// time, then look at what their next command sees
+	r := testRunner(t, `
+tasks:
+  all:
+    deps: [a, b]
+  a:
+    cmds:
+      - "export SHARED=a; cd a; sleep 0.2; echo a:$SHARED:$(basename $PWD)"
+      - "echo a-next:${SHARED:-unset}:$(basename $PWD)"
+      - {cmd: "echo a-dir:${SHARED:-unset}:$(basename $PWD)", dir: a}
+  b:
+    cmds:
+      - "export SHARED=b; cd b; sleep 0.2; echo b:$SHARED:$(basename $PWD)"
+      - "echo b-next:${SHARED:-unset}:$(basename $PWD)"
+      - {cmd: "echo b-dir:${SHARED:-unset}:$(basename $PWD)", dir: b}
+`)
+	r.Dir = root
+	r.Env = map[string]string{"BASE": "1"}
+	var out syncBuffer
+	r.CommandOut = &out
+	if err := r.RunTask("all"); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Fields(out.buf.String())
+	sort.Strings(lines)
+	base := filepath.Base(root)
+	want := []string{
+		"a-dir:unset:a", "a-next:unset:" + base, "a:a:a",
+		"b-dir:unset:b", "b-next:unset:" + base, "b:b:b",
+	}
+	if strings.Join(lines, " ") != strings.Join(want, " ") {
+		t.Errorf("deps saw:\n%s\nwant:\n%s", strings.Join(lines, "\n"), strings.Join(want, "\n"))
+	}
+	if value, set := os.LookupEnv("SHARED"); set {
+		t.Errorf("SHARED leaked into t's environment as %q", value)
+	}
+}
+
+func TestSetCommandEnvGivesEachCommandItsOwnCopy(t *testing.T) {
+	r := NewRunner(&Config{})
+	r.Env = map[string]string{"SHARED": "runner"}
+
+	first, second := exec.Command("first"), exec.Command("second")
+	second.Dir = "own"
+	r.setCommandEnv(first)
+	r.setCommandEnv(second)
+
+	first.Env[len(first.Env)-1] = "SHARED=changed"
+	if got := second.Env[len(second.Env)-1]; got != "SHARED=runner" {
+		t.Errorf("second command sees %s after the first one's env changed", got)
+	}
+	if first.Dir != r.Dir || second.Dir != "own" {
+		t.Errorf("dirs are %q and %q, want %q and own", first.Dir, second.Dir, r.Dir)
+	}
+}
@@ -0,0 +1,94 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrTaskNotFound is returned when a task name does not exist in the config
+	ErrTaskNotFound = errors.New("task not found")
+	// ErrProcessNotFound is returned when no detached process matches an identifier
+	ErrProcessNotFound = errors.New("no detached process found")
+	// ErrMissingRequirement is returned when a tool listed in requires is not on PATH
+	ErrMissingRequirement = errors.New("missing required tool")
+	// ErrAlreadyRunning is returned when a task to detach already runs in the background
+	ErrAlreadyRunning = errors.New("task is already running in the background")
+	// ErrTaskLocked is returned when another run holds the lock of a task
+	ErrTaskLocked = errors.New("task is locked by another run")
+	// ErrUnknownProfile is returned when --config-profile names a profile the config doesn't define
+	ErrUnknownProfile = errors.New("unknown profile")
+	// ErrMaxRuntime is returned when the run's overall time budget runs out
+	ErrMaxRuntime = errors.New("run exceeded its --max-runtime budget")
+	// ErrUnknownSignal is returned for a signal that can't be sent on this platform
+	ErrUnknownSignal = errors.New("unsupported signal")
+	// ErrInputDisabled is returned when a task needs input or a confirmation
+	// while prompting is turned off with NonInteractive
+	ErrInputDisabled = errors.New("prompts are disabled by --interactive=false")
+)
+
+// TaskFailedError is returned when a command of a task exits unsuccessfully.
+// Timeout is set when the command was killed because a timeout expired.
+type TaskFailedError struct {
+	Task string
+	Cmd  string
+	// Location is where the command is written, such as tasks.yaml:42
+	Location string
+	ExitCode int
+	Timeout  time.Duration
+	// Idle is set when the command was killed for producing no output for
+	// Timeout
+	Idle bool
+	Err  error
+}
+
+func (e *TaskFailedError) Error() string {
+	where := ""
+	if e.Location != "" {
+		where = fmt.Sprintf(" (task %s, %s)", e.Task, e.Location)
+	}
+	if e.Idle {
+		return fmt.Sprintf("command produced no output for %v: %s%s", e.Timeout, e.Cmd, where)
+	}
+	if e.Timeout > 0 {
+		return fmt.Sprintf("command timed out after %v: %s%s", e.Timeout, e.Cmd, where)
+	}
+	return fmt.Sprintf("command failed: %s%s", e.Cmd, where)
+}
+
+func (e *TaskFailedError) Unwrap() error {
+	return e.Err
+}
+
+// ConfigError is returned when a configuration cannot be read or parsed.
+// Source is the file path or URL the config was loaded from, if any.
+type ConfigError struct {
+	Source string
+	Err    error
+}
+
+func (e *ConfigError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ConfigError) Unwrap() error {
+	return e.Err
+}
+
+// newTaskFailedError builds a TaskFailedError, extracting the exit code from
+// the error returned by exec when the process ran and exited non-zero
+func newTaskFailedError(taskName string, cmdStr string, err error) *TaskFailedError {
+	exitCode := -1
+	var exitErr interface{ ExitCode() int }
+	if errors.As(err, &exitErr) {
+		exitCode = exitErr.ExitCode()
+	}
+
+	return &TaskFailedError{
+		Task:     taskName,
+		Cmd:      cmdStr,
+		ExitCode: exitCode,
+		Err:      err,
+	}
+}
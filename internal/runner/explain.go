@@ -0,0 +1,155 @@
+package runner
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Decision is one entry of the log built by Explain
+type Decision struct {
+	Task string
+	// Depth is how many tasks up the chain requested this one
+	Depth  int
+	Reason string
+}
+
+// Explain plans a run of taskName without executing anything and returns
+// the reasons each task in the plan will run, be skipped or fail. Tasks are
+// listed in the order they would start.
+func (r *Runner) Explain(taskName string) ([]Decision, error) {
+	planned := make(map[string]bool)
+	r.mutex.RLock()
+	for name, ran := range r.Ran {
+		planned[name] = ran
+	}
+	r.mutex.RUnlock()
+	if r.Force {
+		delete(planned, taskName)
+	}
+
+	var log []Decision
+	err := r.explainTask(taskName, nil, false, planned, &log)
+	return log, err
+}
+
+// explainTask mirrors runTaskWithSync, recording decisions instead of
+// running commands
+func (r *Runner) explainTask(taskName string, path []string, again bool, planned map[string]bool, log *[]Decision) error {
+	depth := len(path)
+	decide := func(format string, args ...any) {
+		*log = append(*log, Decision{Task: taskName, Depth: depth, Reason: fmt.Sprintf(format, args...)})
+	}
+
+	for _, parent := range path {
+		if parent == taskName {
+			return fmt.Errorf("dependency cycle detected: %s -> %s", strings.Join(path, " -> "), taskName)
+		}
+	}
+	path = append(path[:len(path):len(path)], taskName)
+
+	if planned[taskName] && !r.ForceAll && !again {
+		decide("skipped: already ran in this invocation")
+		return nil
+	}
+
+	task, exists := r.Config.Tasks[taskName]
+	if !exists {
+		return r.taskNotFound(taskName)
+	}
+
+	if missing := MissingRequirements(task); len(missing) > 0 {
+		decide("will fail: required tools not on PATH: %s", strings.Join(missing, ", "))
+		return nil
+	}
+
+	vars, err := r.taskVars(taskName, task)
+	if err != nil {
+		return fmt.Errorf("failed to expand vars of task %s: %w", taskName, err)
+	}
+	deps, err := r.expandDeps(task, vars)
+	if err != nil {
+		return fmt.Errorf("failed to expand deps of task %s: %w", taskName, err)
+	}
+
+	switch {
+	case depth == 0 && r.Force:
+		decide("will run: --force ignores earlier runs")
+	case depth == 0:
+		decide("will run: requested")
+	case r.ForceAll:
+		decide("will run: --force-all runs shared tasks every time")
+	case again && planned[taskName]:
+		decide("will run again: %s needs it always", path[len(path)-2])
+	default:
+		decide("will run: needed by %s", path[len(path)-2])
+	}
+	if len(deps) > 0 && depth == 0 && r.NoDeps {
+		decide("skips its deps %s: --no-deps", strings.Join(task.Deps.Names(), ", "))
+		deps = nil
+	} else if len(deps) > 0 {
+		stages := make([]string, len(deps))
+		for i, stage := range deps {
+			names := make([]string, len(stage))
+			for j, dep := range stage {
+				names[j] = dep.String()
+			}
+			stages[i] = strings.Join(names, ", ")
+		}
+		decide("depends on %s", strings.Join(stages, ", then "))
+	}
+
+	if task.When != "" {
+		decide("skipped after its deps unless when holds: %s", task.When)
+	}
+
+	for _, stage := range deps {
+		for _, dep := range stage {
+			if err := r.explainTask(dep.Task, path, dep.Always, planned, log); err != nil {
+				return err
+			}
+		}
+	}
+	planned[taskName] = true
+
+	if task.Confirm.Enabled {
+		if r.AssumeYes {
+			decide("confirmation prompt answered by --yes")
+		} else {
+			decide("asks for confirmation before running")
+		}
+	}
+	if task.Lock.Enabled {
+		decide("takes lock %s while it runs", task.Lock.LockName(taskName))
+	}
+	if len(task.Interactive) > 0 {
+		decide("prompts for %s", strings.Join(task.InteractiveNames(), ", "))
+	}
+	if !task.NoGlobalHooks && (len(r.Config.BeforeTask) > 0 || len(r.Config.AfterTask) > 0) {
+		decide("wrapped by the global before_task/after_task hooks")
+	}
+	if len(task.Before) > 0 || len(task.After) > 0 {
+		decide("runs %d before and %d after hook command(s)", len(task.Before), len(task.After))
+	}
+	if len(task.Matrix) > 0 {
+		decide("runs %d command(s) for each of %d matrix combination(s)", len(task.Cmds), len(matrixCombinations(task.Matrix)))
+	} else if task.Parallel {
+		decide("runs %d command(s) in parallel", len(task.Cmds))
+	} else {
+		decide("runs %d command(s)", len(task.Cmds))
+	}
+
+	for _, command := range task.Cmds {
+		if command.Task == "" {
+			continue
+		}
+		name, err := r.expandVars(command.Task, vars)
+		if err != nil {
+			return err
+		}
+		if err := r.explainTask(name, path, false, planned, log); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
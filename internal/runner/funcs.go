@@ -0,0 +1,51 @@
+package runner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// templateFuncs returns the functions available in commands, vars, deps and
+// when conditions besides the vars themselves:
+//
+//   - fileContents "VERSION" (or readFile) is the file's contents with
+//     surrounding whitespace trimmed
+//   - fileExists "go.mod" reports whether the file exists
+//
+// Relative paths resolve against Dir, like the paths inside commands.
+func (r *Runner) templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"fileContents": r.fileContents,
+		"readFile":     r.fileContents,
+		"fileExists":   r.fileExists,
+	}
+}
+
+// fileContents reads a file for the fileContents template function
+func (r *Runner) fileContents(name string) (string, error) {
+	data, err := os.ReadFile(r.resolvePath(name))
+	if os.IsNotExist(err) {
+		return "", fmt.Errorf("file %s not found", name)
+	}
+	if err != nil {
+		return "", fmt.Errorf("cannot read file %s: %w", name, err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// fileExists checks a file for the fileExists template function
+func (r *Runner) fileExists(name string) bool {
+	_, err := os.Stat(r.resolvePath(name))
+	return err == nil
+}
+
+// resolvePath returns name resolved against Dir when it is relative
+func (r *Runner) resolvePath(name string) string {
+	if filepath.IsAbs(name) || r.Dir == "" {
+		return name
+	}
+	return filepath.Join(r.Dir, name)
+}
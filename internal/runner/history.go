@@ -0,0 +1,72 @@
+package runner
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// HistoryEntry is one run recorded in the history file
+type HistoryEntry struct {
+	StartedAt time.Time     `json:"started_at"`
+	Task      string        `json:"task"`
+	Duration  time.Duration `json:"duration_ns"`
+	ExitCode  int           `json:"exit_code"`
+	Error     string        `json:"error,omitempty"`
+}
+
+// Succeeded reports whether the recorded run finished without an error
+func (e HistoryEntry) Succeeded() bool {
+	return e.ExitCode == 0
+}
+
+// AppendHistory adds an entry to the history file. Each entry is written
+// with a single append so concurrent runs don't interleave their lines.
+func (r *Runner) AppendHistory(entry HistoryEntry) error {
+	historyFile := r.historyFile()
+	if err := os.MkdirAll(filepath.Dir(historyFile), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(historyFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(data, '\n'))
+	return err
+}
+
+// ReadHistory returns the recorded runs, oldest first. A missing history
+// file means no runs were recorded yet. Lines that don't parse are skipped.
+func (r *Runner) ReadHistory() ([]HistoryEntry, error) {
+	file, err := os.Open(r.historyFile())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []HistoryEntry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry HistoryEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, scanner.Err()
+}
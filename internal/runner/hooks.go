@@ -0,0 +1,96 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// runGlobalHooks wraps run with the config's before_task and after_task
+// hooks, unless the task opted out. They see the task's vars, so TASK holds
+// the name of the task as in its commands.
+func (r *Runner) runGlobalHooks(taskName string, task Task, vars map[string]string, interactiveInputs map[string]string, path []string, run func() error) error {
+	if task.NoGlobalHooks || (len(r.Config.BeforeTask) == 0 && len(r.Config.AfterTask) == 0) {
+		return run()
+	}
+	return r.runWithHooks(taskName, r.Config.BeforeTask, r.Config.AfterTask, vars, interactiveInputs, path, run)
+}
+
+// runDetachedHooks runs the before_task hooks and the before commands of a
+// detached task in the foreground, where they would run before its
+// commands. After hooks can't run, as t exits before the task does, so
+// they only get a warning.
+func (r *Runner) runDetachedHooks(taskName string, task Task, vars map[string]string) error {
+	if r.hasAfterHooks(task) {
+		fmt.Fprintf(r.Out, "⚠️  After hooks of %s don't run, as a detached task outlives t\n", taskName)
+	}
+
+	if !task.NoGlobalHooks && len(r.Config.BeforeTask) > 0 {
+		if err := r.executeCommandsWithInteractive(taskName, r.Config.BeforeTask, vars, nil, []string{taskName}); err != nil {
+			return fmt.Errorf("before hook: %w", err)
+		}
+	}
+	if len(task.Before) > 0 {
+		if err := r.executeCommandsWithInteractive(taskName, task.Before, vars, nil, []string{taskName}); err != nil {
+			return fmt.Errorf("before hook: %w", err)
+		}
+	}
+	return nil
+}
+
+// hasAfterHooks reports whether after hooks would run once the task is done
+func (r *Runner) hasAfterHooks(task Task) bool {
+	return len(task.After) > 0 || (!task.NoGlobalHooks && len(r.Config.AfterTask) > 0)
+}
+
+// runWithHooks runs the before hooks, then run, then the after hooks. A
+// failing before hook skips run. After hooks always run, like a finally
+// block, with EXIT_CODE set to the exit code of the failure or 0 and
+// TASK_FAILED to true or false.
+func (r *Runner) runWithHooks(taskName string, before, after []Command, vars map[string]string, interactiveInputs map[string]string, path []string, run func() error) error {
+	var err error
+	if len(before) > 0 {
+		if beforeErr := r.executeCommandsWithInteractive(taskName, before, vars, interactiveInputs, path); beforeErr != nil {
+			err = fmt.Errorf("before hook: %w", beforeErr)
+		}
+	}
+	if err == nil {
+		err = run()
+	}
+
+	if len(after) == 0 {
+		return err
+	}
+
+	afterVars := make(map[string]string, len(vars)+2)
+	for name, value := range vars {
+		afterVars[name] = value
+	}
+	afterVars["EXIT_CODE"] = strconv.Itoa(hookExitCode(err))
+	afterVars["TASK_FAILED"] = strconv.FormatBool(err != nil)
+
+	if afterErr := r.executeCommandsWithInteractive(taskName, after, afterVars, interactiveInputs, path); afterErr != nil {
+		if err != nil {
+			// Keep the original failure, it is the one the user cares about
+			fmt.Fprintf(r.taskOut(taskName), "⚠️  after hook of %s failed: %v\n", taskName, afterErr)
+			return err
+		}
+		return fmt.Errorf("after hook: %w", afterErr)
+	}
+
+	return err
+}
+
+// hookExitCode is the EXIT_CODE passed to after hooks: 0 on success, the
+// failing command's exit code when known, 1 otherwise
+func hookExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	var taskErr *TaskFailedError
+	if errors.As(err, &taskErr) && taskErr.ExitCode > 0 {
+		return taskErr.ExitCode
+	}
+	return 1
+}
@@ -0,0 +1,53 @@
+package runner
+
+import (
+	"context"
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+// idleWatchdog cancels a command that writes no output for longer than its
+// timeout. Every write through one of its writers restarts the countdown.
+type idleWatchdog struct {
+	timeout time.Duration
+	timer   *time.Timer
+	fired   atomic.Bool
+}
+
+// newIdleWatchdog starts the countdown and returns a context that is
+// cancelled when it runs out
+func newIdleWatchdog(ctx context.Context, timeout time.Duration) (*idleWatchdog, context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	w := &idleWatchdog{timeout: timeout}
+	w.timer = time.AfterFunc(timeout, func() {
+		w.fired.Store(true)
+		cancel()
+	})
+	return w, ctx, func() {
+		w.timer.Stop()
+		cancel()
+	}
+}
+
+// writer wraps out so that writes to it count as activity
+func (w *idleWatchdog) writer(out io.Writer) io.Writer {
+	return &idleWriter{out: out, watchdog: w}
+}
+
+// Fired reports whether the command was cancelled for being idle
+func (w *idleWatchdog) Fired() bool {
+	return w.fired.Load()
+}
+
+type idleWriter struct {
+	out      io.Writer
+	watchdog *idleWatchdog
+}
+
+func (w *idleWriter) Write(p []byte) (int, error) {
+	if !w.watchdog.Fired() {
+		w.watchdog.timer.Reset(w.watchdog.timeout)
+	}
+	return w.out.Write(p)
+}
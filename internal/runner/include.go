@@ -0,0 +1,354 @@
+package runner
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// includeTag marks a value read from the files matching a glob pattern, as
+// in `tasks: !include tasks.d/*.yaml`
+const includeTag = "!include"
+
+// configTree is a config parsed into a single YAML mapping, with its
+// documents combined and its includes resolved
+type configTree struct {
+	root *yaml.Node
+	// combined is set when the tree differs from the config's own text,
+	// because it had includes or several documents
+	combined bool
+	// origins maps the nodes read from included files to those files
+	origins map[*yaml.Node]string
+}
+
+// includeLoader resolves the includes of a config
+type includeLoader struct {
+	origins map[*yaml.Node]string
+	// stack holds the absolute paths of the files being included, to catch
+	// cycles
+	stack []string
+	// remote refuses includes, which a remote config can't resolve
+	remote bool
+}
+
+// parseConfigTree parses a config read from path, "" for one that isn't read
+// from a file, combining its documents and resolving its includes. Includes
+// are relative to the including file, or to the current directory.
+func parseConfigTree(data []byte, path string) (*configTree, error) {
+	docs, err := parseDocuments(data)
+	if err != nil || len(docs) == 0 {
+		return &configTree{}, err
+	}
+
+	loader := &includeLoader{
+		origins: make(map[*yaml.Node]string),
+		remote:  strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://"),
+	}
+	if path != "" && !loader.remote {
+		if abs, err := filepath.Abs(path); err == nil {
+			loader.stack = []string{abs}
+		}
+	}
+
+	dir := "."
+	if path != "" && !loader.remote {
+		dir = filepath.Dir(path)
+	}
+	for _, doc := range docs {
+		if err := loader.resolve(doc, dir); err != nil {
+			return nil, err
+		}
+	}
+
+	// Each document can add tasks, vars and other sections of its own
+	root := docs[0]
+	for i, doc := range docs[1:] {
+		if err := combineNodes(root, doc, true); err != nil {
+			return nil, fmt.Errorf("document %d: %w", i+2, err)
+		}
+	}
+
+	return &configTree{
+		root:     root,
+		combined: len(docs) > 1 || len(loader.origins) > 0,
+		origins:  loader.origins,
+	}, nil
+}
+
+// parseDocuments parses every document of a YAML stream, returning the root
+// node of each
+func parseDocuments(data []byte) ([]*yaml.Node, error) {
+	var docs []*yaml.Node
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc yaml.Node
+		err := decoder.Decode(&doc)
+		if err == io.EOF {
+			return docs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(doc.Content) > 0 {
+			docs = append(docs, doc.Content[0])
+		}
+	}
+}
+
+// resolve replaces the include nodes under node with the contents of the
+// files they match
+func (l *includeLoader) resolve(node *yaml.Node, dir string) error {
+	if node.Tag != includeTag {
+		for _, child := range node.Content {
+			if err := l.resolve(child, dir); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := l.include(node, dir); err != nil {
+		return fmt.Errorf("line %d: %s %s: %w", node.Line, includeTag, node.Value, err)
+	}
+	return nil
+}
+
+// include replaces an include node with the contents of the files it matches,
+// combined in order of their names
+func (l *includeLoader) include(node *yaml.Node, dir string) error {
+	if l.remote {
+		return errors.New("includes aren't allowed in a remote config")
+	}
+	if node.Kind != yaml.ScalarNode || node.Value == "" {
+		return errors.New("takes a file name or glob pattern")
+	}
+
+	pattern := node.Value
+	if !filepath.IsAbs(pattern) {
+		pattern = filepath.Join(dir, pattern)
+	}
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return err
+	}
+	if len(matches) == 0 {
+		return fmt.Errorf("no file matches %s", pattern)
+	}
+	sort.Strings(matches)
+
+	var combined *yaml.Node
+	for _, file := range matches {
+		docs, err := l.load(file)
+		if err != nil {
+			return err
+		}
+		for _, doc := range docs {
+			if combined == nil {
+				combined = doc
+				continue
+			}
+			if err := combineNodes(combined, doc, false); err != nil {
+				return fmt.Errorf("%s: %w", file, err)
+			}
+		}
+	}
+	if combined == nil {
+		return fmt.Errorf("%s is empty", pattern)
+	}
+
+	*node = *combined
+	return nil
+}
+
+// load parses an included file and resolves its own includes
+func (l *includeLoader) load(file string) ([]*yaml.Node, error) {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return nil, err
+	}
+	for i, including := range l.stack {
+		if including == abs {
+			cycle := append(append([]string(nil), l.stack[i:]...), abs)
+			for j := range cycle {
+				cycle[j] = filepath.Base(cycle[j])
+			}
+			return nil, fmt.Errorf("include cycle: %s", strings.Join(cycle, " -> "))
+		}
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	docs, err := parseDocuments(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+
+	l.stack = append(l.stack, abs)
+	defer func() { l.stack = l.stack[:len(l.stack)-1] }()
+	for _, doc := range docs {
+		l.mark(doc, file)
+		if err := l.resolve(doc, filepath.Dir(file)); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+	}
+	return docs, nil
+}
+
+// mark records file as the origin of node and everything under it
+func (l *includeLoader) mark(node *yaml.Node, file string) {
+	l.origins[node] = file
+	for _, child := range node.Content {
+		l.mark(child, file)
+	}
+}
+
+// combineNodes adds the contents of src to dst: the entries of a mapping or
+// the items of a sequence. A key both define is an error, unless sections is
+// set and both values are mappings, whose entries are then combined.
+func combineNodes(dst, src *yaml.Node, sections bool) error {
+	switch {
+	case dst.Kind == yaml.SequenceNode && src.Kind == yaml.SequenceNode:
+		dst.Content = append(dst.Content, src.Content...)
+		return nil
+	case dst.Kind != yaml.MappingNode || src.Kind != yaml.MappingNode:
+		return fmt.Errorf("line %d: can't combine %s with %s", src.Line, nodeKind(src), nodeKind(dst))
+	}
+
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		key, value := src.Content[i], src.Content[i+1]
+		existing := mappingValue(dst, key.Value)
+		switch {
+		case existing == nil:
+			dst.Content = append(dst.Content, key, value)
+		case sections && existing.Kind == yaml.MappingNode && value.Kind == yaml.MappingNode:
+			if err := combineNodes(existing, value, false); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("line %d: %s is already defined", key.Line, key.Value)
+		}
+	}
+	return nil
+}
+
+// nodeKind names the kind of a YAML node for errors
+func nodeKind(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "a mapping"
+	case yaml.SequenceNode:
+		return "a list"
+	default:
+		return "a single value"
+	}
+}
+
+// checkKnownFields reports keys of mappings under node that don't match a
+// field of the struct they decode into, like decoding with KnownFields does.
+// Types with their own UnmarshalYAML check themselves or, for commands, are
+// checked by checkCommandFields.
+func (t *configTree) checkKnownFields(node *yaml.Node, typ reflect.Type) error {
+	if typ.Implements(reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem()) ||
+		reflect.PointerTo(typ).Implements(reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem()) {
+		return nil
+	}
+
+	switch typ.Kind() {
+	case reflect.Pointer:
+		return t.checkKnownFields(node, typ.Elem())
+	case reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			return nil
+		}
+		for _, item := range node.Content {
+			if err := t.checkKnownFields(item, typ.Elem()); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+		for i := 1; i < len(node.Content); i += 2 {
+			if err := t.checkKnownFields(node.Content[i], typ.Elem()); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+		fields := make(map[string]reflect.Type)
+		for i := 0; i < typ.NumField(); i++ {
+			name, _, _ := strings.Cut(typ.Field(i).Tag.Get("yaml"), ",")
+			if name != "" && name != "-" {
+				fields[name] = typ.Field(i).Type
+			}
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i]
+			fieldType, ok := fields[key.Value]
+			if !ok {
+				return t.errorAt(key, fmt.Errorf("line %d: field %s not found in type %s", key.Line, key.Value, typ))
+			}
+			if err := t.checkKnownFields(node.Content[i+1], fieldType); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// errorAt prefixes err with the included file node comes from, if any
+func (t *configTree) errorAt(node *yaml.Node, err error) error {
+	if file := t.origins[node]; file != "" {
+		return fmt.Errorf("%s: %w", file, err)
+	}
+	return err
+}
+
+// recordOrigins sets the Source of the tasks and commands that come from
+// included files to those files
+func (t *configTree) recordOrigins(config *Config) {
+	config.BeforeTask = t.commandOrigins(mappingValue(t.root, "before_task"), config.BeforeTask)
+	config.AfterTask = t.commandOrigins(mappingValue(t.root, "after_task"), config.AfterTask)
+
+	tasks := mappingValue(t.root, "tasks")
+	if tasks == nil || tasks.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(tasks.Content); i += 2 {
+		name, node := tasks.Content[i].Value, tasks.Content[i+1]
+		task, ok := config.Tasks[name]
+		if !ok {
+			continue
+		}
+		task.Source = t.origins[tasks.Content[i]]
+		task.Cmds = t.commandOrigins(mappingValue(node, "cmds"), task.Cmds)
+		task.Before = t.commandOrigins(mappingValue(node, "before"), task.Before)
+		task.After = t.commandOrigins(mappingValue(node, "after"), task.After)
+		config.Tasks[name] = task
+	}
+}
+
+// commandOrigins sets the Source of the commands decoded from a command
+// list node that come from included files
+func (t *configTree) commandOrigins(list *yaml.Node, commands []Command) []Command {
+	if list == nil || list.Kind != yaml.SequenceNode || len(list.Content) != len(commands) {
+		return commands
+	}
+	for i, item := range list.Content {
+		commands[i].Source = t.origins[item]
+	}
+	return commands
+}
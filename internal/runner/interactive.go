@@ -0,0 +1,55 @@
+package runner
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// interactiveRefPattern matches $name references to interactive inputs
+var interactiveRefPattern = regexp.MustCompile(`\$([A-Za-z_][A-Za-z0-9_]*)`)
+
+// InteractiveNames returns the names of the task's interactive inputs in the
+// order they are asked: the order they are written in the config, then any
+// others alphabetically
+func (t Task) InteractiveNames() []string {
+	names := make([]string, 0, len(t.Interactive))
+	seen := make(map[string]bool, len(t.Interactive))
+	for _, name := range t.InteractiveOrder {
+		if _, ok := t.Interactive[name]; ok && !seen[name] {
+			names = append(names, name)
+			seen[name] = true
+		}
+	}
+
+	var rest []string
+	for name := range t.Interactive {
+		if !seen[name] {
+			rest = append(rest, name)
+		}
+	}
+	sort.Strings(rest)
+
+	return append(names, rest...)
+}
+
+// expandPrompt expands a prompt's message or default with the task's vars
+// and the answers given so far. Referencing an input that is asked later is
+// an error, since its value isn't known yet.
+func (r *Runner) expandPrompt(text string, task Task, vars map[string]string, answers map[string]string) (string, error) {
+	for _, match := range interactiveRefPattern.FindAllStringSubmatch(text, -1) {
+		name := match[1]
+		if _, asked := answers[name]; asked {
+			continue
+		}
+		if _, ok := task.Interactive[name]; ok {
+			return "", fmt.Errorf("prompt %q refers to $%s, which is asked later", text, name)
+		}
+	}
+
+	expanded, err := r.expandVars(text, vars)
+	if err != nil {
+		return "", err
+	}
+	return r.expandVarsWithInteractive(expanded, answers)
+}
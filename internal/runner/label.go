@@ -0,0 +1,61 @@
+package runner
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// TaskResult is the outcome of one task run by RunTasks
+type TaskResult struct {
+	Task     string
+	Duration time.Duration
+	Err      error
+}
+
+// TasksWithLabel returns the names of the tasks carrying label, sorted
+func (c *Config) TasksWithLabel(label string) []string {
+	var names []string
+	for name, task := range c.Tasks {
+		if task.HasLabel(label) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RunTasks runs each task in turn, or up to Parallel at the same time when it
+// is above 1. Every task runs even when another fails, and tasks shared as
+// dependencies still run only once. Results are in the order of names.
+func (r *Runner) RunTasks(names []string) []TaskResult {
+	results := make([]TaskResult, len(names))
+	run := func(i int) {
+		start := time.Now()
+		err := r.RunTask(names[i])
+		results[i] = TaskResult{Task: names[i], Duration: time.Since(start), Err: err}
+	}
+
+	if r.Parallel > 1 {
+		var wg sync.WaitGroup
+		slots := make(chan struct{}, r.Parallel)
+		for i := range names {
+			flush := r.separateOutput(names[i], r.Out)
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				slots <- struct{}{}
+				defer func() { <-slots }()
+				run(i)
+				flush()
+			}(i)
+		}
+		wg.Wait()
+	} else {
+		for i := range names {
+			run(i)
+		}
+	}
+
+	return results
+}
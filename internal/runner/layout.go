@@ -0,0 +1,34 @@
+package runner
+
+import "gopkg.in/yaml.v3"
+
+// recordTaskLayout stores what decoding into maps loses: the line each task
+// is defined on and the written order of its interactive inputs
+func (t *configTree) recordTaskLayout(config *Config) {
+	if t.root == nil {
+		return
+	}
+
+	tasks := mappingValue(t.root, "tasks")
+	if tasks == nil || tasks.Kind != yaml.MappingNode {
+		return
+	}
+
+	for i := 1; i < len(tasks.Content); i += 2 {
+		name := tasks.Content[i-1].Value
+		task, ok := config.Tasks[name]
+		if !ok {
+			continue
+		}
+		task.Line = tasks.Content[i-1].Line
+
+		task.InteractiveOrder = nil
+		if interactive := mappingValue(tasks.Content[i], "interactive"); interactive != nil && interactive.Kind == yaml.MappingNode {
+			for j := 0; j < len(interactive.Content); j += 2 {
+				task.InteractiveOrder = append(task.InteractiveOrder, interactive.Content[j].Value)
+			}
+		}
+
+		config.Tasks[name] = task
+	}
+}
@@ -0,0 +1,36 @@
+//go:build !windows
+
+package runner
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// lockFile takes an exclusive lock on the file, blocking until it is available
+func lockFile(file *os.File) error {
+	return syscall.Flock(int(file.Fd()), syscall.LOCK_EX)
+}
+
+// unlockFile releases a lock taken with lockFile
+func unlockFile(file *os.File) error {
+	return syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+}
+
+// tryLockFile takes an exclusive lock on the file if no one holds it,
+// reporting whether it did
+func tryLockFile(file *os.File) (bool, error) {
+	err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if err == syscall.EWOULDBLOCK {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// inheritLock hands a locked file to a command, which then holds the lock
+// after t closes its own copy of the file
+func inheritLock(cmd *exec.Cmd, file *os.File) bool {
+	cmd.ExtraFiles = append(cmd.ExtraFiles, file)
+	return true
+}
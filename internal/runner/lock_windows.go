@@ -0,0 +1,63 @@
+//go:build windows
+
+package runner
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	kernel32         = syscall.NewLazyDLL("kernel32.dll")
+	procLockFileEx   = kernel32.NewProc("LockFileEx")
+	procUnlockFileEx = kernel32.NewProc("UnlockFileEx")
+)
+
+const (
+	lockfileFailImmediately = 0x00000001
+	lockfileExclusiveLock   = 0x00000002
+
+	errorLockViolation syscall.Errno = 33
+)
+
+// lockFile takes an exclusive lock on the file, blocking until it is available
+func lockFile(file *os.File) error {
+	var overlapped syscall.Overlapped
+	ret, _, err := procLockFileEx.Call(file.Fd(), lockfileExclusiveLock, 0, 1, 0, uintptr(unsafe.Pointer(&overlapped)))
+	if ret == 0 {
+		return err
+	}
+	return nil
+}
+
+// unlockFile releases a lock taken with lockFile
+func unlockFile(file *os.File) error {
+	var overlapped syscall.Overlapped
+	ret, _, err := procUnlockFileEx.Call(file.Fd(), 0, 1, 0, uintptr(unsafe.Pointer(&overlapped)))
+	if ret == 0 {
+		return err
+	}
+	return nil
+}
+
+// tryLockFile takes an exclusive lock on the file if no one holds it,
+// reporting whether it did
+func tryLockFile(file *os.File) (bool, error) {
+	var overlapped syscall.Overlapped
+	ret, _, err := procLockFileEx.Call(file.Fd(), lockfileExclusiveLock|lockfileFailImmediately, 0, 1, 0, uintptr(unsafe.Pointer(&overlapped)))
+	if ret == 0 {
+		if err == errorLockViolation {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// inheritLock can't hand a locked file to a command on Windows, so a
+// detached task's lock is only held while t starts it
+func inheritLock(cmd *exec.Cmd, file *os.File) bool {
+	return false
+}
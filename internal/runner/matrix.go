@@ -0,0 +1,113 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// matrixCombinations expands a matrix into every combination of its values.
+// Keys are processed in alphabetical order so the result is deterministic.
+func matrixCombinations(matrix map[string][]string) []map[string]string {
+	keys := make([]string, 0, len(matrix))
+	for key := range matrix {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	combinations := []map[string]string{{}}
+	for _, key := range keys {
+		var next []map[string]string
+		for _, combination := range combinations {
+			for _, value := range matrix[key] {
+				extended := make(map[string]string, len(combination)+1)
+				for k, v := range combination {
+					extended[k] = v
+				}
+				extended[key] = value
+				next = append(next, extended)
+			}
+		}
+		combinations = next
+	}
+
+	return combinations
+}
+
+// matrixLabel formats a combination as "key=value" pairs in key order
+func matrixLabel(combination map[string]string) string {
+	keys := make([]string, 0, len(combination))
+	for key := range combination {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = key + "=" + combination[key]
+	}
+	return strings.Join(pairs, " ")
+}
+
+// runMatrix executes the task's commands once per matrix combination with the
+// combination's values added to the vars. Combinations run sequentially unless
+// Runner.Parallel is greater than one. Every combination runs and its result
+// is reported separately.
+func (r *Runner) runMatrix(taskName string, task Task, vars map[string]string, interactiveInputs map[string]string, path []string) error {
+	combinations := matrixCombinations(task.Matrix)
+	results := make([]error, len(combinations))
+
+	runCombination := func(i int) {
+		combination := combinations[i]
+		combinationVars := make(map[string]string, len(vars)+len(combination))
+		for name, value := range vars {
+			combinationVars[name] = value
+		}
+		for name, value := range combination {
+			combinationVars[name] = value
+		}
+
+		fmt.Fprintf(r.taskOut(taskName), "🧮 %s [%s]\n", taskName, matrixLabel(combination))
+		results[i] = r.runTaskCmds(taskName, task, combinationVars, interactiveInputs, path)
+	}
+
+	if r.Parallel > 1 {
+		var wg sync.WaitGroup
+		slots := make(chan struct{}, r.Parallel)
+		for i := range combinations {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				slots <- struct{}{}
+				defer func() { <-slots }()
+				runCombination(i)
+			}(i)
+		}
+		wg.Wait()
+	} else {
+		for i := range combinations {
+			runCombination(i)
+		}
+	}
+
+	// Report every combination's result
+	var errs []error
+	fmt.Fprintf(r.taskOut(taskName), "🧮 Matrix results for %s:\n", taskName)
+	for i, combination := range combinations {
+		label := matrixLabel(combination)
+		if results[i] != nil {
+			fmt.Fprintf(r.taskOut(taskName), "   ❌ %s: %v\n", label, results[i])
+			errs = append(errs, fmt.Errorf("[%s] %w", label, results[i]))
+		} else {
+			fmt.Fprintf(r.taskOut(taskName), "   ✅ %s\n", label)
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%d of %d matrix combinations failed:\n%w", len(errs), len(combinations), errors.Join(errs...))
+	}
+
+	return nil
+}
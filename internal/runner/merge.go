@@ -0,0 +1,91 @@
+package runner
+
+// MergeConfigs layers the project config over a user-global one. Project
+// tasks, vars and profiles override global ones of the same name, and the
+// project's hooks, notify_url, state_dir, timeout and shell args are used
+// when set. History is recorded when either config turns it on. Tasks and
+// commands taken from the global config get their Source set to source,
+// unless they come from a file it includes.
+func MergeConfigs(global *Config, source string, project *Config) *Config {
+	merged := &Config{
+		Version:    project.Version,
+		Vars:       make(map[string]string, len(global.Vars)+len(project.Vars)),
+		BeforeTask: project.BeforeTask,
+		AfterTask:  project.AfterTask,
+		NotifyURL:  project.NotifyURL,
+		History:    project.History || global.History,
+		StateDir:   project.StateDir,
+		Profiles:   make(map[string]Profile, len(global.Profiles)+len(project.Profiles)),
+		Tasks:      make(map[string]Task, len(global.Tasks)+len(project.Tasks)),
+	}
+
+	if len(merged.BeforeTask) == 0 {
+		merged.BeforeTask = withSource(global.BeforeTask, source)
+	}
+	if len(merged.AfterTask) == 0 {
+		merged.AfterTask = withSource(global.AfterTask, source)
+	}
+	if merged.NotifyURL == "" {
+		merged.NotifyURL = global.NotifyURL
+	}
+	if merged.StateDir == "" {
+		merged.StateDir = global.StateDir
+	}
+	merged.Timeout = project.Timeout
+	if merged.Timeout == "" {
+		merged.Timeout = global.Timeout
+	}
+	merged.ShellArgs, merged.PowerShellArgs = project.ShellArgs, project.PowerShellArgs
+	if len(merged.ShellArgs) == 0 {
+		merged.ShellArgs = global.ShellArgs
+	}
+	if len(merged.PowerShellArgs) == 0 {
+		merged.PowerShellArgs = global.PowerShellArgs
+	}
+
+	for name, value := range global.Vars {
+		merged.Vars[name] = value
+	}
+	for name, value := range project.Vars {
+		merged.Vars[name] = value
+	}
+
+	for name, profile := range global.Profiles {
+		merged.Profiles[name] = profile
+	}
+	for name, profile := range project.Profiles {
+		merged.Profiles[name] = profile
+	}
+
+	for name, task := range global.Tasks {
+		if task.Source == "" {
+			task.Source = source
+		}
+		task.Global = true
+		task.Cmds = withSource(task.Cmds, source)
+		task.Before = withSource(task.Before, source)
+		task.After = withSource(task.After, source)
+		merged.Tasks[name] = task
+	}
+	for name, task := range project.Tasks {
+		merged.Tasks[name] = task
+	}
+
+	return merged
+}
+
+// withSource returns a copy of commands with their Source set to source,
+// keeping the Source of commands from included files
+func withSource(commands []Command, source string) []Command {
+	if commands == nil {
+		return nil
+	}
+	sourced := make([]Command, len(commands))
+	for i, command := range commands {
+		if command.Source == "" {
+			command.Source = source
+		}
+		sourced[i] = command
+	}
+	return sourced
+}
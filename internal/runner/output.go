@@ -0,0 +1,293 @@
+package runner
+
+import (
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"sync"
+)
+
+// prefixWriter prefixes every line written through it with a fixed label so
+// that interleaved output from several commands stays attributable
+type prefixWriter struct {
+	out    io.Writer
+	prefix []byte
+	buf    []byte
+	mutex  sync.Mutex
+}
+
+// newPrefixWriter creates a writer that prints lines as "[prefix] line",
+// with the label in the given ANSI color unless color is empty
+func newPrefixWriter(out io.Writer, prefix string, color string) *prefixWriter {
+	label := "[" + prefix + "]"
+	if color != "" {
+		label = "\x1b[" + color + "m" + label + "\x1b[0m"
+	}
+	return &prefixWriter{
+		out:    out,
+		prefix: []byte(label + " "),
+	}
+}
+
+// Write buffers partial lines and forwards every complete line with the prefix
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		if err := w.writeLine(w.buf[:i+1]); err != nil {
+			return len(p), err
+		}
+		w.buf = w.buf[i+1:]
+	}
+
+	return len(p), nil
+}
+
+// Flush writes out any trailing output that did not end with a newline
+func (w *prefixWriter) Flush() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if len(w.buf) == 0 {
+		return nil
+	}
+
+	line := append(w.buf, '\n')
+	w.buf = nil
+	return w.writeLine(line)
+}
+
+func (w *prefixWriter) writeLine(line []byte) error {
+	_, err := w.out.Write(append(append([]byte{}, w.prefix...), line...))
+	return err
+}
+
+// LineWriter is a writer that holds a last partial line until it is flushed
+type LineWriter interface {
+	io.Writer
+	Flush() error
+}
+
+// NewPrefixWriter returns a writer printing the lines written to it as
+// "[name] line", colored like the prefixes of parallel deps
+func (r *Runner) NewPrefixWriter(out io.Writer, name string) LineWriter {
+	return newPrefixWriter(out, name, r.prefixColor(name))
+}
+
+// prefixColors are the ANSI colors given to dependency prefixes. Red is
+// left out so prefixes aren't mistaken for errors.
+var prefixColors = []string{"36", "35", "33", "32", "34", "96", "95", "93", "92", "94"}
+
+// prefixColor returns the color for a dependency prefix, the same one for
+// a name every time, or "" when colors are off
+func (r *Runner) prefixColor(name string) string {
+	if !r.PrefixColors {
+		return ""
+	}
+	hash := fnv.New32a()
+	hash.Write([]byte(name))
+	return prefixColors[hash.Sum32()%uint32(len(prefixColors))]
+}
+
+// outputGroup collects the output of a task for GroupOutput, to be printed
+// in one piece once the task is done. The standard and error output of
+// commands are kept apart from the runner's messages, so each is printed
+// where it belongs.
+type outputGroup struct {
+	chunks []outputChunk
+	mutex  sync.Mutex
+}
+
+// outputKind tells where a chunk of grouped output is printed
+type outputKind int
+
+const (
+	// messageOutput is the runner's messages, printed with the header
+	messageOutput outputKind = iota
+	// commandOutput is the standard output of commands, for CommandOut
+	commandOutput
+	// errorOutput is the error output of commands, for ErrOut
+	errorOutput
+)
+
+// outputChunk is a run of output of one kind
+type outputChunk struct {
+	kind outputKind
+	data []byte
+}
+
+func (g *outputGroup) Write(p []byte) (int, error) {
+	return g.add(messageOutput, p)
+}
+
+// add appends output to the group, to the last chunk when it is of the same
+// kind
+func (g *outputGroup) add(kind outputKind, p []byte) (int, error) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if n := len(g.chunks); n > 0 && g.chunks[n-1].kind == kind {
+		g.chunks[n-1].data = append(g.chunks[n-1].data, p...)
+	} else {
+		g.chunks = append(g.chunks, outputChunk{kind: kind, data: append([]byte(nil), p...)})
+	}
+	return len(p), nil
+}
+
+// groupCommandWriter adds the standard or error output of commands to a
+// group
+type groupCommandWriter struct {
+	group *outputGroup
+	kind  outputKind
+}
+
+func (w groupCommandWriter) Write(p []byte) (int, error) {
+	return w.group.add(w.kind, p)
+}
+
+// separateOutput keeps the output of a task that runs side by side with
+// others apart, grouped with GroupOutput or prefixed with PrefixDeps. The
+// returned function prints a group to out once the task is done.
+func (r *Runner) separateOutput(taskName string, out io.Writer) func() {
+	r.outputMutex.Lock()
+	defer r.outputMutex.Unlock()
+
+	switch {
+	case r.GroupOutput:
+		if r.groups == nil {
+			r.groups = make(map[string]*outputGroup)
+		}
+		group := &outputGroup{}
+		r.groups[taskName] = group
+		return func() {
+			group.mutex.Lock()
+			defer group.mutex.Unlock()
+			if len(group.chunks) == 0 {
+				return
+			}
+			r.printGroup(out, fmt.Sprintf("📋 Output of %s:\n", taskName), group.chunks)
+		}
+	case r.PrefixDeps:
+		if r.prefixes == nil {
+			r.prefixes = make(map[string]string)
+		}
+		r.prefixes[taskName] = taskName
+	}
+	return func() {}
+}
+
+// printGroup prints the header and chunks of a group: messages to out,
+// command output to CommandOut and error output to ErrOut, or into the group
+// out is part of
+func (r *Runner) printGroup(out io.Writer, header string, chunks []outputChunk) {
+	if parent, ok := out.(*outputGroup); ok {
+		parent.add(messageOutput, []byte(header))
+		for _, chunk := range chunks {
+			parent.add(chunk.kind, chunk.data)
+		}
+		return
+	}
+
+	// Groups printed at the same time don't mix
+	r.printMutex.Lock()
+	defer r.printMutex.Unlock()
+	out.Write([]byte(header))
+	for _, chunk := range chunks {
+		switch chunk.kind {
+		case commandOutput:
+			r.commandOut().Write(chunk.data)
+		case errorOutput:
+			r.ErrOut.Write(chunk.data)
+		default:
+			out.Write(chunk.data)
+		}
+	}
+}
+
+// inheritOutput gives a task the output prefix or group of the task that
+// started it, unless it already has its own
+func (r *Runner) inheritOutput(taskName string, parent string) {
+	r.outputMutex.Lock()
+	defer r.outputMutex.Unlock()
+
+	if _, ok := r.prefixes[taskName]; !ok {
+		if prefix, ok := r.prefixes[parent]; ok {
+			r.prefixes[taskName] = prefix
+		}
+	}
+	if _, ok := r.groups[taskName]; !ok {
+		if group, ok := r.groups[parent]; ok {
+			r.groups[taskName] = group
+		}
+	}
+}
+
+// outputPrefix returns the prefix for the output of a task, or "" when its
+// output isn't prefixed
+func (r *Runner) outputPrefix(taskName string) string {
+	r.outputMutex.Lock()
+	defer r.outputMutex.Unlock()
+
+	return r.prefixes[taskName]
+}
+
+// taskOut returns where the messages about a task go: its group when the
+// output is grouped, r.Out otherwise
+func (r *Runner) taskOut(taskName string) io.Writer {
+	r.outputMutex.Lock()
+	defer r.outputMutex.Unlock()
+
+	if group, ok := r.groups[taskName]; ok {
+		return group
+	}
+	return r.Out
+}
+
+// taskCommandOut is like taskOut for the standard output of the task's
+// commands, which goes to CommandOut when it isn't grouped
+func (r *Runner) taskCommandOut(taskName string) io.Writer {
+	r.outputMutex.Lock()
+	defer r.outputMutex.Unlock()
+
+	if group, ok := r.groups[taskName]; ok {
+		return groupCommandWriter{group, commandOutput}
+	}
+	return r.commandOut()
+}
+
+// taskErrOut is like taskCommandOut for error output, which goes to ErrOut.
+// Grouped error output goes into the same group, so the order of lines is
+// kept, and is printed to ErrOut with it.
+func (r *Runner) taskErrOut(taskName string) io.Writer {
+	r.outputMutex.Lock()
+	defer r.outputMutex.Unlock()
+
+	if group, ok := r.groups[taskName]; ok {
+		return groupCommandWriter{group, errorOutput}
+	}
+	return r.ErrOut
+}
+
+// commandOut returns where the standard output of commands goes
+func (r *Runner) commandOut() io.Writer {
+	if r.CommandOut != nil {
+		return r.CommandOut
+	}
+	return r.Out
+}
+
+// pathOut returns where output about the deps of the last task in path goes:
+// the output of that task, or r.Out at the top
+func (r *Runner) pathOut(path []string) io.Writer {
+	if len(path) == 0 {
+		return r.Out
+	}
+	return r.taskOut(path[len(path)-1])
+}
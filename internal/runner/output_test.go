@@ -0,0 +1,38 @@
+package runner
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestGroupedErrorOutputGoesToErrOut(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("cmd strings run through PowerShell on Windows")
+	}
+	r := testRunner(t, `
+tasks:
+  lint:
+    cmds: ["echo lint ok; echo lint warning >&2"]
+  test:
+    cmds: ["echo test ok"]
+  all:
+    deps: [lint, test]
+`)
+	var out, commandOut, errOut syncBuffer
+	r.Out, r.CommandOut, r.ErrOut = &out, &commandOut, &errOut
+	r.GroupOutput = true
+	if err := r.RunTask("all"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := commandOut.buf.String(); strings.Contains(got, "warning") || !strings.Contains(got, "lint ok") || !strings.Contains(got, "test ok") {
+		t.Errorf("command output is %q, want the standard output of both deps", got)
+	}
+	if got := errOut.buf.String(); got != "lint warning\n" {
+		t.Errorf("error output is %q, want the warning of lint", got)
+	}
+	if got := out.buf.String(); !strings.Contains(got, "📋 Output of lint:") {
+		t.Errorf("messages lack the group header: %q", got)
+	}
+}
@@ -0,0 +1,174 @@
+//go:build !windows
+
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// processGroupSetup describes how setProcessGroup sets up detached processes
+const processGroupSetup = "new process group (setpgid), stopped by signalling the whole group"
+
+// setProcessGroup starts the command in its own process group so that the
+// whole tree can be signalled when the detached task is stopped
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// setCommandCancel makes cancelling a foreground command, by a timeout or
+// --max-runtime, stop every process it started and not just the shell.
+// Without a terminal the command gets a process group of its own to signal.
+// At a terminal it stays in t's group, so it can read from the terminal and
+// Ctrl+C reaches it, and its descendants are looked up with ps instead.
+func (r *Runner) setCommandCancel(cmd *exec.Cmd) {
+	if stat, err := os.Stdin.Stat(); err != nil || stat.Mode()&os.ModeCharDevice == 0 {
+		cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+		cmd.Cancel = func() error {
+			return stopProcesses([]int{-cmd.Process.Pid}, DefaultStopGrace)
+		}
+		return
+	}
+	cmd.Cancel = func() error {
+		pids := append(descendantPIDs(cmd.Process.Pid), cmd.Process.Pid)
+		return stopProcesses(pids, DefaultStopGrace)
+	}
+}
+
+// stopProcesses sends SIGTERM to the targets, negative for a process group,
+// and SIGKILL to whatever of them is still there after grace
+func stopProcesses(targets []int, grace time.Duration) error {
+	err := os.ErrProcessDone
+	for _, target := range targets {
+		if syscall.Kill(target, syscall.SIGTERM) == nil {
+			err = nil
+		}
+		// A paused process only acts on SIGTERM once it is continued
+		syscall.Kill(target, syscall.SIGCONT)
+	}
+	if errors.Is(err, os.ErrProcessDone) {
+		return err
+	}
+
+	time.AfterFunc(grace, func() {
+		for _, target := range targets {
+			syscall.Kill(target, syscall.SIGKILL)
+		}
+	})
+	return nil
+}
+
+// descendantPIDs lists the children of a process, their children and so on
+func descendantPIDs(pid int) []int {
+	output, err := exec.Command("ps", "-A", "-o", "pid=,ppid=").Output()
+	if err != nil {
+		return nil
+	}
+	children := make(map[int][]int)
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		child, childErr := strconv.Atoi(fields[0])
+		parent, parentErr := strconv.Atoi(fields[1])
+		if childErr == nil && parentErr == nil {
+			children[parent] = append(children[parent], child)
+		}
+	}
+
+	var pids []int
+	queue := children[pid]
+	for len(queue) > 0 {
+		pids = append(pids, queue[0])
+		queue = append(queue[1:], children[queue[0]]...)
+	}
+	return pids
+}
+
+// checkGroupPID refuses PIDs that kill(2) doesn't treat as a single process
+// group when negated: -1 is every process of the user and 0 is t's own group
+func checkGroupPID(pid int) error {
+	if pid <= 1 {
+		return fmt.Errorf("refusing to signal process group of PID %d", pid)
+	}
+	return nil
+}
+
+// terminateProcess sends SIGTERM to the process group (and the process itself),
+// waits up to grace for it to exit and escalates to SIGKILL if it is still alive
+func (r *Runner) terminateProcess(pid int, grace time.Duration) error {
+	if err := checkGroupPID(pid); err != nil {
+		return err
+	}
+
+	// Negative PID targets the whole process group
+	groupErr := syscall.Kill(-pid, syscall.SIGTERM)
+	procErr := syscall.Kill(pid, syscall.SIGTERM)
+	if groupErr != nil && procErr != nil {
+		return procErr
+	}
+	// A paused process only acts on SIGTERM once it is continued
+	syscall.Kill(-pid, syscall.SIGCONT)
+	syscall.Kill(pid, syscall.SIGCONT)
+
+	if r.waitForExit(pid, grace) {
+		return nil
+	}
+
+	fmt.Fprintf(r.Out, "⚠️  Process %d did not exit within %v, sending SIGKILL\n", pid, grace)
+	groupErr = syscall.Kill(-pid, syscall.SIGKILL)
+	procErr = syscall.Kill(pid, syscall.SIGKILL)
+	if groupErr != nil && procErr != nil {
+		return procErr
+	}
+
+	return nil
+}
+
+// signalNames maps the signal names accepted by --signal to their signals
+var signalNames = map[string]syscall.Signal{
+	"HUP":   syscall.SIGHUP,
+	"INT":   syscall.SIGINT,
+	"QUIT":  syscall.SIGQUIT,
+	"KILL":  syscall.SIGKILL,
+	"USR1":  syscall.SIGUSR1,
+	"USR2":  syscall.SIGUSR2,
+	"ALRM":  syscall.SIGALRM,
+	"TERM":  syscall.SIGTERM,
+	"CONT":  syscall.SIGCONT,
+	"STOP":  syscall.SIGSTOP,
+	"TSTP":  syscall.SIGTSTP,
+	"WINCH": syscall.SIGWINCH,
+}
+
+// signalProcess sends a signal, given by name (HUP, SIGHUP) or number, to
+// the process group and the process itself
+func (r *Runner) signalProcess(pid int, signal string) error {
+	name := strings.TrimPrefix(strings.ToUpper(signal), "SIG")
+	sig, ok := signalNames[name]
+	if !ok {
+		number, err := strconv.Atoi(signal)
+		if err != nil || number <= 0 {
+			return fmt.Errorf("%w: %s", ErrUnknownSignal, signal)
+		}
+		sig = syscall.Signal(number)
+	}
+	if err := checkGroupPID(pid); err != nil {
+		return err
+	}
+
+	groupErr := syscall.Kill(-pid, sig)
+	procErr := syscall.Kill(pid, sig)
+	if groupErr != nil && procErr != nil {
+		return procErr
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+//go:build !windows
+
+package runner
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// useStdin makes file t's stdin for the rest of the test, as a terminal or
+// a pipe decides how commands are stopped
+func useStdin(t *testing.T, file *os.File) {
+	t.Helper()
+	stdin := os.Stdin
+	os.Stdin = file
+	t.Cleanup(func() { os.Stdin = stdin })
+}
+
+// stdinModes runs a test with stdin as a pipe, where commands get a process
+// group of their own, and as a character device, where they don't
+func stdinModes(t *testing.T, test func(t *testing.T)) {
+	t.Run("pipe", func(t *testing.T) {
+		reader, writer, err := os.Pipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { reader.Close(); writer.Close() })
+		useStdin(t, reader)
+		test(t)
+	})
+	t.Run("device", func(t *testing.T) {
+		null, err := os.Open(os.DevNull)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { null.Close() })
+		useStdin(t, null)
+		test(t)
+	})
+}
+
+// backgroundChild is a command leaving a sleep running in the background,
+// its PID written to pidFile
+func backgroundChild(pidFile string) string {
+	return "sleep 30 & echo $! > " + pidFile + "; wait"
+}
+
+// assertGone fails unless the process in pidFile exits soon. A zombie
+// counts as gone, as nothing in the test reaps orphans.
+func assertGone(t *testing.T, pidFile string) {
+	t.Helper()
+	content, err := os.ReadFile(pidFile)
+	if err != nil {
+		t.Fatalf("reading PID: %v", err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(content)))
+	if err != nil {
+		t.Fatalf("parsing PID: %v", err)
+	}
+
+	for deadline := time.Now().Add(3 * time.Second); time.Now().Before(deadline); time.Sleep(50 * time.Millisecond) {
+		state, _ := exec.Command("ps", "-o", "stat=", "-p", strconv.Itoa(pid)).Output()
+		if trimmed := strings.TrimSpace(string(state)); trimmed == "" || strings.HasPrefix(trimmed, "Z") {
+			return
+		}
+	}
+	exec.Command("kill", strconv.Itoa(pid)).Run()
+	t.Fatalf("child %d is still running", pid)
+}
+
+func TestIdleTimeoutStopsChildren(t *testing.T) {
+	stdinModes(t, func(t *testing.T) {
+		pidFile := filepath.Join(t.TempDir(), "pid")
+		r := testRunner(t, `
+tasks:
+  build:
+    idle_timeout: 300ms
+    cmds: ["`+backgroundChild(pidFile)+`"]
+`)
+
+		if err := r.RunTask("build"); err == nil {
+			t.Fatal("expected the idle timeout to fail the task")
+		}
+		assertGone(t, pidFile)
+	})
+}
+
+func TestMaxRuntimeStopsChildren(t *testing.T) {
+	stdinModes(t, func(t *testing.T) {
+		dir := t.TempDir()
+		unitPID, e2ePID := filepath.Join(dir, "unit"), filepath.Join(dir, "e2e")
+		r := testRunner(t, `
+tasks:
+  unit:
+    cmds: ["`+backgroundChild(unitPID)+`"]
+  e2e:
+    cmds: ["sh -c '`+backgroundChild(e2ePID)+`'"]
+  ci:
+    deps: [unit, e2e]
+`)
+		ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+		defer cancel()
+		r.Context = ctx
+
+		if err := r.RunTask("ci"); err == nil {
+			t.Fatal("expected the run budget to fail the task")
+		}
+		assertGone(t, unitPID)
+		assertGone(t, e2ePID)
+	})
+}
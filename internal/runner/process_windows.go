@@ -0,0 +1,70 @@
+//go:build windows
+
+package runner
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// processGroupSetup describes how setProcessGroup sets up detached processes
+const processGroupSetup = "new process group (CREATE_NEW_PROCESS_GROUP), stopped with taskkill /T"
+
+// setProcessGroup creates a new process group so that the whole tree can be
+// terminated when the detached task is stopped
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP,
+	}
+}
+
+// setCommandCancel makes cancelling a foreground command, by a timeout or
+// --max-runtime, stop every process it started and not just the shell.
+// Console programs rarely handle a graceful taskkill, so the tree is killed.
+func (r *Runner) setCommandCancel(cmd *exec.Cmd) {
+	cmd.Cancel = func() error {
+		return r.signalProcess(cmd.Process.Pid, "KILL")
+	}
+}
+
+// terminateProcess asks the process tree to close with a graceful taskkill,
+// waits up to grace for it to exit and forces termination with /F otherwise
+func (r *Runner) terminateProcess(pid int, grace time.Duration) error {
+	// Without /F taskkill sends a close request the process can handle
+	gracefulCmd := exec.Command("taskkill", "/T", "/PID", strconv.Itoa(pid))
+	if err := gracefulCmd.Run(); err == nil && r.waitForExit(pid, grace) {
+		return nil
+	}
+
+	fmt.Fprintf(r.Out, "⚠️  Process %d did not exit gracefully, forcing termination\n", pid)
+	forceCmd := exec.Command("taskkill", "/F", "/T", "/PID", strconv.Itoa(pid))
+	if err := forceCmd.Run(); err != nil {
+		return fmt.Errorf("failed to kill process tree %d: %w", pid, err)
+	}
+
+	return nil
+}
+
+// signalProcess delivers the only signals Windows has an equivalent for:
+// TERM as a graceful taskkill and KILL as a forced one, both for the whole
+// process tree
+func (r *Runner) signalProcess(pid int, signal string) error {
+	var args []string
+	switch strings.TrimPrefix(strings.ToUpper(signal), "SIG") {
+	case "TERM", "15":
+		args = []string{"/T", "/PID", strconv.Itoa(pid)}
+	case "KILL", "9":
+		args = []string{"/F", "/T", "/PID", strconv.Itoa(pid)}
+	default:
+		return fmt.Errorf("%w on Windows: %s (only TERM and KILL can be sent)", ErrUnknownSignal, signal)
+	}
+
+	if err := exec.Command("taskkill", args...).Run(); err != nil {
+		return fmt.Errorf("failed to signal process tree %d: %w", pid, err)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// lockRegistry takes an exclusive lock on the detached process registry in
+// dir so that concurrent t invocations never see half-written entries. The
+// returned function releases the lock.
+func lockRegistry(dir string) (func(), error) {
+	file, err := os.OpenFile(filepath.Join(dir, ".lock"), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := lockFile(file); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return func() {
+		unlockFile(file)
+		file.Close()
+	}, nil
+}
+
+// writeFileAtomic writes data to a temporary file in the same directory and
+// renames it into place, so readers either see the old or the new content.
+// The data is synced to disk before the rename so a crash can never leave a
+// truncated file behind under the final name.
+func writeFileAtomic(filename string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(filename), filepath.Base(filename)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	if err := os.Chmod(tmpName, perm); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+
+	if err := os.Rename(tmpName, filename); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+
+	return nil
+}
+
+// removeStaleTempFiles deletes temporary files left behind by writers that
+// were interrupted before renaming them into place. It must be called with
+// the registry lock held so in-flight writes are not affected.
+func removeStaleTempFiles(dir string) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.tmp"))
+	if err != nil {
+		return
+	}
+	for _, file := range files {
+		os.Remove(file)
+	}
+}
@@ -0,0 +1,117 @@
+package runner
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// RepeatResult counts the iterations run by RepeatTask
+type RepeatResult struct {
+	Runs   int
+	Passed int
+	Failed int
+}
+
+// RepeatTask runs a task count times, or until it fails when count is zero,
+// stopping at the first failure. Each iteration starts with an empty Ran map
+// so the task and its dependencies run again. When Parallel is above 1, up to
+// that many iterations run at the same time, each with its own state.
+func (r *Runner) RepeatTask(taskName string, count int) (RepeatResult, error) {
+	var (
+		result   RepeatResult
+		firstErr error
+		mu       sync.Mutex
+	)
+
+	record := func(iteration int, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		result.Runs++
+		if err == nil {
+			result.Passed++
+			return
+		}
+		result.Failed++
+		if firstErr == nil {
+			firstErr = fmt.Errorf("iteration %d: %w", iteration, err)
+		}
+	}
+	failed := func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return firstErr != nil
+	}
+
+	if r.Parallel > 1 {
+		var wg sync.WaitGroup
+		slots := make(chan struct{}, r.Parallel)
+		for i := 1; count == 0 || i <= count; i++ {
+			slots <- struct{}{}
+			if failed() {
+				<-slots
+				break
+			}
+
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				defer func() { <-slots }()
+
+				iteration := r.iterationRunner()
+				fmt.Fprintf(r.Out, "🔁 Iteration %s\n", iterationLabel(i, count))
+				record(i, iteration.RunTask(taskName))
+
+				r.mutex.Lock()
+				r.Timings = append(r.Timings, iteration.Timings...)
+				r.mutex.Unlock()
+			}(i)
+		}
+		wg.Wait()
+	} else {
+		for i := 1; count == 0 || i <= count; i++ {
+			r.mutex.Lock()
+			r.Ran = make(map[string]bool)
+			r.mutex.Unlock()
+
+			fmt.Fprintf(r.Out, "🔁 Iteration %s\n", iterationLabel(i, count))
+			record(i, r.RunTask(taskName))
+			if failed() {
+				break
+			}
+		}
+	}
+
+	fmt.Fprintf(r.Out, "🔁 Repeat results for %s: %d passed, %d failed (%d runs)\n",
+		taskName, result.Passed, result.Failed, result.Runs)
+
+	return result, firstErr
+}
+
+// iterationRunner returns a runner with the same configuration and settings
+// as r but its own run state. Every exported field is copied, so settings
+// added later carry over too, except Ran and Timings; unexported fields hold
+// the state of a run and start out empty.
+func (r *Runner) iterationRunner() *Runner {
+	iteration := &Runner{}
+	settings, copied := reflect.ValueOf(r).Elem(), reflect.ValueOf(iteration).Elem()
+	for i := 0; i < settings.NumField(); i++ {
+		if settings.Type().Field(i).IsExported() {
+			copied.Field(i).Set(settings.Field(i))
+		}
+	}
+	iteration.Ran = make(map[string]bool)
+	iteration.Timings = nil
+	iteration.input = r.stdinReader()
+	return iteration
+}
+
+// iterationLabel formats an iteration number as "3/50", or just "3" when
+// repeating until failure
+func iterationLabel(i, count int) string {
+	if count == 0 {
+		return fmt.Sprint(i)
+	}
+	return fmt.Sprintf("%d/%d", i, count)
+}
@@ -0,0 +1,266 @@
+package runner
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Reporter receives the results of a run as it goes, to print its progress
+// or write the results in a format such as JUnit XML or TAP once the run is
+// over. Tasks running in parallel call it from several goroutines at once.
+type Reporter interface {
+	// OnTaskStart is called when a task starts running its commands. run
+	// tells this run of the task apart in the calls that follow, as a task
+	// can run more than once at a time, such as in repeated runs.
+	OnTaskStart(run int, task string)
+	// OnCommandEnd is called when a command of a task exits, with the
+	// command line as shown in the output. label marks the output of the
+	// command, such as the number of a parallel command, or is "".
+	OnCommandEnd(run int, task string, label string, command string, duration time.Duration, err error)
+	// OnTaskEnd is called when a task is done, err is nil when it succeeded
+	OnTaskEnd(run int, task string, duration time.Duration, err error)
+	// Finish writes the report
+	Finish() error
+}
+
+// ConsoleReporter is the default reporter, which prints the progress of a
+// run: the tasks as they start and the commands that succeed. The runner
+// always reports to it, besides Runner.Reporter.
+type ConsoleReporter struct {
+	// Out returns the writer the output of a task goes to
+	Out func(task string) io.Writer
+}
+
+func (c *ConsoleReporter) OnTaskStart(run int, task string) {
+	fmt.Fprintf(c.Out(task), "🔧 Running task: %s\n", task)
+}
+
+func (c *ConsoleReporter) OnCommandEnd(run int, task string, label string, command string, duration time.Duration, err error) {
+	if err != nil {
+		return
+	}
+	if label != "" {
+		label = "[" + label + "] "
+	}
+	fmt.Fprintf(c.Out(task), "✅ %sdone\n", label)
+}
+
+// OnTaskEnd prints nothing, as the outcome of a run is printed by its caller
+func (c *ConsoleReporter) OnTaskEnd(run int, task string, duration time.Duration, err error) {}
+
+func (c *ConsoleReporter) Finish() error {
+	return nil
+}
+
+// ReporterNames lists the formats NewReporter supports
+var ReporterNames = []string{"junit", "tap"}
+
+// NewReporter returns the reporter for a format in ReporterNames, writing
+// its report to out
+func NewReporter(format string, out io.Writer) (Reporter, error) {
+	switch format {
+	case "junit":
+		return &JUnitReporter{out: out}, nil
+	case "tap":
+		return &TAPReporter{out: out}, nil
+	default:
+		return nil, fmt.Errorf("unknown reporter %q (available: %s)", format, strings.Join(ReporterNames, ", "))
+	}
+}
+
+// taskResult is what a reporter knows about one task run
+type taskResult struct {
+	task     string
+	duration time.Duration
+	err      error
+	commands []commandResult
+}
+
+// commandResult is one command of a task run
+type commandResult struct {
+	command  string
+	duration time.Duration
+	err      error
+}
+
+// resultCollector implements the event methods of Reporter by collecting
+// the task results in the order the tasks end
+type resultCollector struct {
+	mutex   sync.Mutex
+	running map[int]*taskResult
+	results []*taskResult
+}
+
+func (c *resultCollector) OnTaskStart(run int, task string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.running == nil {
+		c.running = make(map[int]*taskResult)
+	}
+	c.running[run] = &taskResult{task: task}
+}
+
+func (c *resultCollector) OnCommandEnd(run int, task string, label string, command string, duration time.Duration, err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if result, ok := c.running[run]; ok {
+		result.commands = append(result.commands, commandResult{command, duration, err})
+	}
+}
+
+func (c *resultCollector) OnTaskEnd(run int, task string, duration time.Duration, err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	result, ok := c.running[run]
+	if !ok {
+		result = &taskResult{task: task}
+	}
+	delete(c.running, run)
+	result.duration, result.err = duration, err
+	c.results = append(c.results, result)
+}
+
+// finished returns the collected results
+func (c *resultCollector) finished() []*taskResult {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return append([]*taskResult(nil), c.results...)
+}
+
+// JUnitReporter writes JUnit XML with a test case for each task run. The
+// commands of a task and their outcome are listed in its system-out.
+type JUnitReporter struct {
+	resultCollector
+	out io.Writer
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name       `xml:"testsuites"`
+	Name     string         `xml:"name,attr"`
+	Tests    int            `xml:"tests,attr"`
+	Failures int            `xml:"failures,attr"`
+	Time     string         `xml:"time,attr"`
+	Suite    junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// Finish writes the XML report
+func (j *JUnitReporter) Finish() error {
+	suite := junitTestSuite{Name: "t"}
+	var total time.Duration
+	for _, result := range j.finished() {
+		testCase := junitTestCase{
+			Name:      result.task,
+			Classname: "t",
+			Time:      junitSeconds(result.duration),
+		}
+		var out strings.Builder
+		for _, command := range result.commands {
+			fmt.Fprintf(&out, "%s (%s, %s)\n", command.command, reportStatus(command.err), command.duration.Round(time.Millisecond))
+		}
+		testCase.SystemOut = out.String()
+		if result.err != nil {
+			testCase.Failure = &junitFailure{Message: result.err.Error(), Text: result.err.Error()}
+			suite.Failures++
+		}
+		suite.Cases = append(suite.Cases, testCase)
+		total += result.duration
+	}
+	suite.Tests = len(suite.Cases)
+	suite.Time = junitSeconds(total)
+
+	data, err := xml.MarshalIndent(junitTestSuites{
+		Name:     "t",
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Time:     suite.Time,
+		Suite:    suite,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(j.out, "%s%s\n", xml.Header, data)
+	return err
+}
+
+// junitSeconds formats a duration in seconds, as JUnit times are
+func junitSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 3, 64)
+}
+
+// TAPReporter writes TAP version 13 with a test point for each task run and
+// its commands in the YAML diagnostics
+type TAPReporter struct {
+	resultCollector
+	out io.Writer
+}
+
+// Finish writes the TAP report
+func (t *TAPReporter) Finish() error {
+	results := t.finished()
+
+	var b strings.Builder
+	b.WriteString("TAP version 13\n")
+	fmt.Fprintf(&b, "1..%d\n", len(results))
+	for i, result := range results {
+		status := "ok"
+		if result.err != nil {
+			status = "not ok"
+		}
+		fmt.Fprintf(&b, "%s %d - %s\n", status, i+1, result.task)
+		b.WriteString("  ---\n")
+		fmt.Fprintf(&b, "  duration_ms: %d\n", result.duration.Milliseconds())
+		if result.err != nil {
+			fmt.Fprintf(&b, "  message: %s\n", strconv.Quote(result.err.Error()))
+		}
+		if len(result.commands) > 0 {
+			b.WriteString("  commands:\n")
+			for _, command := range result.commands {
+				fmt.Fprintf(&b, "    - cmd: %s\n", strconv.Quote(command.command))
+				fmt.Fprintf(&b, "      status: %s\n", strconv.Quote(reportStatus(command.err)))
+				fmt.Fprintf(&b, "      duration_ms: %d\n", command.duration.Milliseconds())
+			}
+		}
+		b.WriteString("  ...\n")
+	}
+
+	_, err := io.WriteString(t.out, b.String())
+	return err
+}
+
+// reportStatus describes the outcome of a command in a report
+func reportStatus(err error) string {
+	if err != nil {
+		return "failed: " + err.Error()
+	}
+	return "ok"
+}
@@ -0,0 +1,88 @@
+package runner
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// checkGolden compares got with testdata/name, or rewrites it with -update
+func checkGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s differs, got:\n%s", path, got)
+	}
+}
+
+func TestJUnitReport(t *testing.T) {
+	var out bytes.Buffer
+	reporter, err := NewReporter("junit", &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Two runs of test at once, as in a repeated run, and a failing build
+	reporter.OnTaskStart(1, "test")
+	reporter.OnTaskStart(2, "test")
+	reporter.OnCommandEnd(1, "test", "", "go test ./...", 1500*time.Millisecond, nil)
+	reporter.OnCommandEnd(2, "test", "", "go test -race ./...", 2*time.Second, nil)
+	reporter.OnTaskEnd(2, "test", 2*time.Second, nil)
+	reporter.OnTaskEnd(1, "test", 1500*time.Millisecond, nil)
+	reporter.OnTaskStart(3, "build")
+	reporter.OnCommandEnd(3, "build", "", "go build -o <bin> .", 250*time.Millisecond, errors.New("exit status 2"))
+	reporter.OnTaskEnd(3, "build", 250*time.Millisecond, errors.New(`task build failed: "go build" & exit status 2`))
+	if err := reporter.Finish(); err != nil {
+		t.Fatal(err)
+	}
+
+	checkGolden(t, "junit.golden", out.Bytes())
+}
+
+func TestConsoleReporterPrintsProgress(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("cmd strings run through PowerShell on Windows")
+	}
+	r := testRunner(t, `
+tasks:
+  build:
+    parallel: true
+    cmds: ["true", "true"]
+  fails:
+    cmds: ["false"]
+`)
+	var out bytes.Buffer
+	r.Out = &out
+	r.Reporter, _ = NewReporter("tap", &bytes.Buffer{})
+
+	if err := r.RunTask("build"); err != nil {
+		t.Fatal(err)
+	}
+	r.RunTask("fails")
+	got := out.String()
+	for _, want := range []string{"🔧 Running task: build\n", "✅ [1] done\n", "✅ [2] done\n", "🔧 Running task: fails\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output lacks %q:\n%s", want, got)
+		}
+	}
+	if strings.Count(got, "done") != 2 {
+		t.Errorf("a failed command was reported done:\n%s", got)
+	}
+}
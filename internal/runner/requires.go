@@ -0,0 +1,29 @@
+package runner
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// MissingRequirements returns the tools listed in the task's requires that
+// can't be found on PATH
+func MissingRequirements(task Task) []string {
+	var missing []string
+	for _, tool := range task.Requires {
+		if _, err := exec.LookPath(tool); err != nil {
+			missing = append(missing, tool)
+		}
+	}
+	return missing
+}
+
+// checkRequires fails with every missing tool at once so they can all be
+// installed before trying again
+func checkRequires(taskName string, task Task) error {
+	missing := MissingRequirements(task)
+	if len(missing) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w for task %s: %s", ErrMissingRequirement, taskName, strings.Join(missing, ", "))
+}
@@ -1,18 +1,30 @@
+// Package runner loads tasks.yaml configurations and executes their tasks.
+//
+// Programs embedding t can build a Config with LoadConfigReader, create a
+// Runner with NewRunner and point Runner.Out at their own writer before
+// calling RunTask. Command output is written to Runner.Out and Runner.ErrOut.
 package runner
 
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
+	"regexp"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
-	"syscall"
+	"sync/atomic"
 	"text/template"
 	"time"
 
@@ -21,24 +33,140 @@ import (
 
 // Task represents a single task configuration
 type Task struct {
-	Desc        string            `yaml:"desc"`
-	Deps        []string          `yaml:"deps"`
-	Cmds        []string          `yaml:"cmds"`
-	Interactive map[string]Prompt `yaml:"interactive"`
+	Desc          string              `yaml:"desc,omitempty"`
+	Deps          Deps                `yaml:"deps,omitempty"`
+	Vars          Vars                `yaml:"vars,omitempty"`
+	Matrix        map[string][]string `yaml:"matrix,omitempty"`
+	Confirm       Confirm             `yaml:"confirm,omitempty"`
+	Cmds          []Command           `yaml:"cmds,omitempty"`
+	Before        []Command           `yaml:"before,omitempty"`
+	After         []Command           `yaml:"after,omitempty"`
+	Interactive   map[string]Prompt   `yaml:"interactive,omitempty"`
+	Internal      bool                `yaml:"internal,omitempty"`
+	Group         string              `yaml:"group,omitempty"`
+	Labels        []string            `yaml:"labels,omitempty"`
+	Timeout       string              `yaml:"timeout,omitempty"`
+	IdleTimeout   string              `yaml:"idle_timeout,omitempty"`
+	NoGlobalHooks bool                `yaml:"no_global_hooks,omitempty"`
+	Requires      []string            `yaml:"requires,omitempty"`
+	Parallel      bool                `yaml:"parallel,omitempty"`
+	LongRunning   bool                `yaml:"long_running,omitempty"`
+	When          string              `yaml:"when,omitempty"`
+	DepsAlways    bool                `yaml:"deps_always,omitempty"`
+	Lock          Lock                `yaml:"lock,omitempty"`
+	// Source names the config file the task came from when it isn't the
+	// project config: the global config, see MergeConfigs, or an included
+	// file
+	Source string `yaml:"-"`
+	// Global marks a task taken from the global config by MergeConfigs
+	Global bool `yaml:"-"`
+	// Line is the line of the config file the task is defined on
+	Line int `yaml:"-"`
+	// InteractiveOrder lists the interactive inputs in the order they are
+	// written in the config, see InteractiveNames
+	InteractiveOrder []string `yaml:"-"`
+}
+
+// HasLabel reports whether the task carries the given label
+func (t Task) HasLabel(label string) bool {
+	for _, l := range t.Labels {
+		if l == label {
+			return true
+		}
+	}
+	return false
+}
+
+// IsInternalTask reports whether a task is a hidden helper, either because it
+// is marked internal or because its name starts with an underscore
+func IsInternalTask(name string, task Task) bool {
+	return task.Internal || strings.HasPrefix(name, "_")
 }
 
 // Prompt represents an interactive prompt configuration
 type Prompt struct {
-	Message  string `yaml:"message"`
-	Required bool   `yaml:"required"`
-	Default  string `yaml:"default"`
+	Message  string `yaml:"message,omitempty"`
+	Required bool   `yaml:"required,omitempty"`
+	Default  string `yaml:"default,omitempty"`
+	// Quote shell-quotes the answer where it is used in a cmd string
+	Quote bool `yaml:"quote,omitempty"`
+}
+
+// Confirm holds a task's confirmation prompt. It is written either as
+// `confirm: true` for a default question or as `confirm: "message"`.
+type Confirm struct {
+	Enabled bool
+	Message string
+}
+
+// UnmarshalYAML accepts both the boolean and the message form
+func (c *Confirm) UnmarshalYAML(value *yaml.Node) error {
+	if value.Tag == "!!bool" {
+		return value.Decode(&c.Enabled)
+	}
+
+	c.Enabled = true
+	return value.Decode(&c.Message)
+}
+
+// MarshalYAML writes the confirmation as true or as its message
+func (c Confirm) MarshalYAML() (any, error) {
+	if c.Message != "" {
+		return c.Message, nil
+	}
+	return c.Enabled, nil
 }
 
 // Config represents the entire tasks.yaml configuration
 type Config struct {
-	Version string            `yaml:"version"`
-	Vars    map[string]string `yaml:"vars"`
-	Tasks   map[string]Task   `yaml:"tasks"`
+	Version    string             `yaml:"version,omitempty"`
+	Vars       Vars               `yaml:"vars,omitempty"`
+	BeforeTask []Command          `yaml:"before_task,omitempty"`
+	AfterTask  []Command          `yaml:"after_task,omitempty"`
+	NotifyURL  string             `yaml:"notify_url,omitempty"`
+	History    bool               `yaml:"history,omitempty"`
+	StateDir   string             `yaml:"state_dir,omitempty"`
+	Profiles   map[string]Profile `yaml:"profiles,omitempty"`
+	Tasks      map[string]Task    `yaml:"tasks,omitempty"`
+	// ShellArgs and PowerShellArgs replace the -c and -Command that cmd
+	// strings are run with by sh and, on Windows, PowerShell
+	ShellArgs      []string `yaml:"shell_args,omitempty"`
+	PowerShellArgs []string `yaml:"powershell_args,omitempty"`
+	// Timeout is the timeout of every task that doesn't set its own
+	Timeout string `yaml:"timeout,omitempty"`
+}
+
+// Profile holds environment specific overrides selected with
+// --config-profile: vars layered over the global vars and environment
+// variables for every command
+type Profile struct {
+	Vars Vars              `yaml:"vars,omitempty"`
+	Env  map[string]string `yaml:"env,omitempty"`
+}
+
+// ApplyProfile layers the named profile's vars over the global vars. It
+// fails with the available profile names when there is no such profile.
+func (c *Config) ApplyProfile(name string) error {
+	profile, ok := c.Profiles[name]
+	if !ok {
+		names := make([]string, 0, len(c.Profiles))
+		for profileName := range c.Profiles {
+			names = append(names, profileName)
+		}
+		sort.Strings(names)
+		if len(names) == 0 {
+			return fmt.Errorf("%w %q: the config defines no profiles", ErrUnknownProfile, name)
+		}
+		return fmt.Errorf("%w %q (available: %s)", ErrUnknownProfile, name, strings.Join(names, ", "))
+	}
+
+	if len(profile.Vars) > 0 && c.Vars == nil {
+		c.Vars = make(map[string]string, len(profile.Vars))
+	}
+	for key, value := range profile.Vars {
+		c.Vars[key] = value
+	}
+	return nil
 }
 
 // DetachedProcess represents a background process
@@ -48,120 +176,483 @@ type DetachedProcess struct {
 	Command   string    `json:"command"`
 	StartedAt time.Time `json:"started_at"`
 	LogFile   string    `json:"log_file"`
+	// Steps is the full resolved command sequence of the task: the setup
+	// commands that ran before it was detached, then Command
+	Steps []string `json:"steps,omitempty"`
+	// LogWriterPID is the process writing LogFile when the task was started
+	// with a DetachedLogWriter, see ReopenLog
+	LogWriterPID int `json:"log_writer_pid,omitempty"`
+	// ScheduledAt is when a run scheduled with ScheduleTask starts the task.
+	// Until then the process only waits.
+	ScheduledAt *time.Time `json:"scheduled_at,omitempty"`
+	// State is ProcessPaused while the process is paused with
+	// PauseDetachedProcess, empty while it runs
+	State string `json:"state,omitempty"`
+}
+
+// ProcessPaused is the State of a detached process that is paused
+const ProcessPaused = "paused"
+
+// DefaultStopGrace is how long a stopped process gets to exit before it is killed
+const DefaultStopGrace = 5 * time.Second
+
+// TaskTiming records how long an executed task took
+type TaskTiming struct {
+	Task     string
+	Duration time.Duration
+	Skipped  bool
+	Failed   bool
+	// WhenFalse marks a task skipped because its when condition didn't hold
+	WhenFalse bool
 }
 
 // Runner handles task execution
 type Runner struct {
-	Config *Config
-	Ran    map[string]bool
-	mutex  sync.RWMutex
+	Config  *Config
+	Ran     map[string]bool
+	Timings []TaskTiming
+	// Out receives the runner's progress messages and the standard output of
+	// commands, ErrOut receives their standard error. Set them to io.Discard
+	// or a buffer to run tasks without printing.
+	Out    io.Writer
+	ErrOut io.Writer
+	// CommandOut receives the standard output of commands in place of Out
+	// when set, so Out can decorate messages without touching that output
+	CommandOut io.Writer
+	// Force runs the requested task even if it already ran with this runner.
+	// ForceAll disables memoization entirely, so a task runs every time it is
+	// requested, including dependencies shared by several tasks.
+	Force    bool
+	ForceAll bool
+	// NoDeps runs the requested task without its deps, for re-running one
+	// step of a chain whose deps are already done. Tasks it runs through a
+	// task command still run their deps.
+	NoDeps bool
+	// AssumeYes answers every confirmation prompt with yes
+	AssumeYes bool
+	// NonInteractive makes tasks that would prompt for input or for a
+	// confirmation not answered by AssumeYes fail with ErrInputDisabled
+	NonInteractive bool
+	// Parallel limits how many matrix combinations or RepeatTask iterations
+	// run at the same time. They run sequentially unless it is above one.
+	Parallel int
+	// ContinueOnError waits for every parallel dependency and reports all
+	// failures together instead of only the first one
+	ContinueOnError bool
+	// Env holds extra environment variables for every command, such as those
+	// loaded with LoadEnvFile. They are also available as template vars,
+	// overriding global vars but not task vars.
+	Env map[string]string
+	// DetachedLogWriter is a command, such as t's :log-writer, that the
+	// output of detached tasks is piped to. The log file's path is appended
+	// to its arguments and it writes the file itself, so it can reopen the
+	// file after it is rotated. The first line of its input lists the
+	// secrets to mask, see ReadLogSecrets. Without it the file is handed to
+	// the task.
+	DetachedLogWriter []string
+	// Trace records task and command spans when set, see NewTrace
+	Trace *Trace
+	// Reporter receives task and command results when set, see NewReporter.
+	// Progress is printed by a ConsoleReporter either way.
+	Reporter Reporter
+	// DetachedStdin is a file whose contents are fed to the stdin of a
+	// detached task. Without it detached tasks read from the null device.
+	DetachedStdin string
+	// Context bounds the whole run, for example by --max-runtime. Commands
+	// are killed once it is done. Nil means no limit.
+	Context context.Context
+	// Timeout bounds every task that doesn't set its own timeout, in place
+	// of the config's timeout
+	Timeout time.Duration
+	// IdleTimeout kills commands that produce no output for this long,
+	// unless their task sets its own idle_timeout
+	IdleTimeout time.Duration
+	// WaitForLock makes a task whose lock another run holds wait for it
+	// instead of failing with ErrTaskLocked
+	WaitForLock bool
+	// AllowDuplicate lets RunTaskDetached start a task that is already
+	// running in the background
+	AllowDuplicate bool
+	// DetachedDryRun makes RunTaskDetached print what it would do and
+	// return the process it would start with PID 0, without running
+	// anything or writing logs and registry files
+	DetachedDryRun bool
+	// ShellArgs overrides the shell arguments of the config for the shell
+	// of this platform, see Config.ShellArgs
+	ShellArgs []string
+	// ConfigSource names the project config, such as tasks.yaml, in the
+	// locations of failed commands. Commands from other configs name their
+	// own Source.
+	ConfigSource string
+	// Dir is the directory commands run in. NewRunner sets it to the
+	// current directory, so a later os.Chdir doesn't move commands of a run
+	// already under way. Empty means the current directory.
+	Dir string
+	// StateDir holds the logs, process registry and history. When empty
+	// they go to .t-logs, .t-processes and .t in the current directory.
+	StateDir string
+	// PrefixDeps prefixes every output line of dependencies that run in
+	// parallel, and of tasks RunTasks runs in parallel, with the task's name
+	// so their output can be told apart
+	PrefixDeps bool
+	// PrefixColors colors the PrefixDeps prefixes, a different color for
+	// each dependency
+	PrefixColors bool
+	// GroupOutput buffers the output of each task that PrefixDeps would
+	// prefix and prints it in one block once the task is done
+	GroupOutput bool
+	mutex       sync.RWMutex
+	input       *bufio.Reader
+	// prefixes and groups map tasks to the prefix or group of their output,
+	// see PrefixDeps and GroupOutput
+	prefixes    map[string]string
+	groups      map[string]*outputGroup
+	outputMutex sync.Mutex
+	// printMutex keeps groups printed at the same time from mixing
+	printMutex sync.Mutex
+	// lockHolders maps the lock files held by tasks of this run to the tasks
+	lockHolders map[string]string
+	// reportRuns maps tasks to their latest run, as numbered for reporters
+	reportRuns map[string]int
+	// inputOnce creates input for the first prompt, which parallel tasks
+	// or iterations may reach at the same time
+	inputOnce sync.Once
 }
 
+// StrictConfig makes loading fail on unknown keys, which are usually typos
+// such as "cmd" instead of "cmds". Disable it to accept configs written for
+// newer versions of t.
+var StrictConfig = true
+
 // LoadConfig loads the tasks.yaml configuration from the specified filename
 func LoadConfig(filename string) (*Config, error) {
 	// Get current working directory
 	cwd, err := os.Getwd()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get current directory: %w", err)
+		return nil, &ConfigError{Source: filename, Err: fmt.Errorf("failed to get current directory: %w", err)}
 	}
 
 	// Construct full path to the config file in current directory
-	configPath := filepath.Join(cwd, filename)
+	configPath := filename
+	if !filepath.IsAbs(configPath) {
+		configPath = filepath.Join(cwd, filename)
+	}
 
 	// Check if file exists
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		return nil, fmt.Errorf("tasks.yaml not found in current directory: %s", cwd)
+		return nil, &ConfigError{Source: filename, Err: fmt.Errorf("%s not found in current directory: %s", filename, cwd)}
 	}
 
-	data, err := os.ReadFile(configPath)
+	file, err := os.Open(configPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read %s: %w", configPath, err)
+		return nil, &ConfigError{Source: filename, Err: fmt.Errorf("failed to read %s: %w", configPath, err)}
+	}
+	defer file.Close()
+
+	config, err := parseConfig(file, filename)
+	if err != nil {
+		return nil, &ConfigError{Source: filename, Err: fmt.Errorf("%s: %w", filename, err)}
+	}
+
+	return config, nil
+}
+
+// LoadConfigReader loads a tasks.yaml configuration from any reader, which
+// lets programs embedding the runner supply configs that are not on disk
+func LoadConfigReader(reader io.Reader) (*Config, error) {
+	config, err := parseConfig(reader, "")
+	if err != nil {
+		return nil, &ConfigError{Err: err}
+	}
+
+	return config, nil
+}
+
+// parseConfig reads and decodes a YAML configuration read from path, which
+// is empty when it doesn't come from a file. Its documents are combined and
+// its includes resolved, see parseConfigTree.
+func parseConfig(reader io.Reader, path string) (*Config, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	tree, err := parseConfigTree(data, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
 	}
 
 	var config Config
-	if err := yaml.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse YAML in %s: %w", filename, err)
+	if tree.combined {
+		// Node.Decode can't reject unknown keys, so the tree is checked first
+		if StrictConfig {
+			if err := tree.checkKnownFields(tree.root, reflect.TypeOf(config)); err != nil {
+				return nil, fmt.Errorf("failed to parse YAML: %w", err)
+			}
+		}
+		if err := tree.root.Decode(&config); err != nil {
+			return nil, fmt.Errorf("failed to parse YAML: %w", err)
+		}
+		tree.recordOrigins(&config)
+	} else {
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(StrictConfig)
+		if err := decoder.Decode(&config); err != nil && err != io.EOF {
+			return nil, fmt.Errorf("failed to parse YAML: %w", err)
+		}
+	}
+
+	tree.recordTaskLayout(&config)
+
+	// Command objects are decoded by a custom unmarshaler, which yaml.v3
+	// does not check for unknown keys, so they are checked separately
+	if StrictConfig {
+		if err := tree.checkCommandFields(); err != nil {
+			return nil, fmt.Errorf("failed to parse YAML: %w", err)
+		}
+	}
+
+	// Catch malformed fields now rather than when the task runs
+	if err := errors.Join(config.checkTasks()...); err != nil {
+		return nil, fmt.Errorf("invalid config:\n%w", err)
 	}
 
 	return &config, nil
 }
 
-// NewRunner creates a new task runner instance
+// LoadConfigURL fetches a tasks.yaml configuration over HTTP(S)
+func LoadConfigURL(url string) (*Config, error) {
+	client := &http.Client{Timeout: 15 * time.Second}
+
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, &ConfigError{Source: url, Err: fmt.Errorf("failed to fetch %s: %w", url, err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &ConfigError{Source: url, Err: fmt.Errorf("failed to fetch %s: %s", url, resp.Status)}
+	}
+
+	config, err := parseConfig(resp.Body, url)
+	if err != nil {
+		return nil, &ConfigError{Source: url, Err: fmt.Errorf("%s: %w", url, err)}
+	}
+
+	return config, nil
+}
+
+// NewRunner creates a new task runner instance that writes to stdout and
+// stderr and runs commands in the current directory. It doesn't check the
+// config: configs from LoadConfig and its variants are already checked,
+// others can be checked with Config.Validate.
 func NewRunner(config *Config) *Runner {
+	dir, _ := os.Getwd()
 	return &Runner{
 		Config: config,
 		Ran:    make(map[string]bool),
+		Out:    MaskingWriter(os.Stdout),
+		ErrOut: MaskingWriter(os.Stderr),
+		Dir:    dir,
 	}
 }
 
 // RunTask executes a task and its dependencies
 func (r *Runner) RunTask(taskName string) error {
-	return r.runTaskWithSync(taskName)
+	if r.Force {
+		r.mutex.Lock()
+		delete(r.Ran, taskName)
+		r.mutex.Unlock()
+	}
+
+	return r.runTaskWithSync(taskName, nil, false)
 }
 
-// runTaskWithSync executes a task with proper synchronization
-func (r *Runner) runTaskWithSync(taskName string) error {
+// runTaskWithSync executes a task with proper synchronization. path holds the
+// chain of tasks that led to this one and is used to detect cycles. A task
+// already run in this invocation is skipped unless again is set, as it is
+// for deps marked always.
+func (r *Runner) runTaskWithSync(taskName string, path []string, again bool) error {
+	for _, parent := range path {
+		if parent == taskName {
+			return fmt.Errorf("dependency cycle detected: %s -> %s", strings.Join(path, " -> "), taskName)
+		}
+	}
+	path = append(path[:len(path):len(path)], taskName)
+	if len(path) > 1 {
+		r.inheritOutput(taskName, path[len(path)-2])
+	}
+
 	// Check if already ran (with read lock)
+	again = again || r.ForceAll
 	r.mutex.RLock()
-	if r.Ran[taskName] {
+	if r.Ran[taskName] && !again {
 		r.mutex.RUnlock()
+		r.recordSkipped(taskName)
 		return nil
 	}
 	r.mutex.RUnlock()
 
 	task, exists := r.Config.Tasks[taskName]
 	if !exists {
-		return fmt.Errorf("task %s not found", taskName)
+		return r.taskNotFound(taskName)
+	}
+
+	if err := r.checkBudget(); err != nil {
+		return fmt.Errorf("%w: task %s not started", err, taskName)
 	}
 
-	// Run dependencies in parallel if possible
-	if len(task.Deps) > 0 {
-		if err := r.runDependenciesParallel(task.Deps); err != nil {
+	if err := checkRequires(taskName, task); err != nil {
+		return err
+	}
+
+	vars, err := r.taskVars(taskName, task)
+	if err != nil {
+		return fmt.Errorf("failed to expand vars of task %s: %w", taskName, err)
+	}
+
+	deps, err := r.expandDeps(task, vars)
+	if err != nil {
+		return fmt.Errorf("failed to expand deps of task %s: %w", taskName, err)
+	}
+
+	// Run dependencies in parallel if possible, one stage after another
+	if len(deps) > 0 && r.NoDeps && len(path) == 1 {
+		fmt.Fprintf(r.taskOut(taskName), "⏭️  Skipping deps of %s (--no-deps): %s\n", taskName, strings.Join(task.Deps.Names(), ", "))
+	} else if len(deps) > 0 {
+		if err := r.runDependencies(deps, path); err != nil {
 			return err
 		}
 	}
 
+	// A task whose condition doesn't hold is skipped once its deps ran. The
+	// condition is a command of the user's, so it runs before the lock is
+	// taken rather than hold up other tasks.
+	holds := true
+	if task.When != "" {
+		holds, err = r.checkWhen(taskName, task, vars)
+		if err != nil {
+			return fmt.Errorf("when of task %s: %w", taskName, err)
+		}
+	}
+
 	// Check again if task was run by a dependency (with write lock)
 	r.mutex.Lock()
-	if r.Ran[taskName] {
+	if r.Ran[taskName] && !again {
 		r.mutex.Unlock()
+		r.recordSkipped(taskName)
 		return nil
 	}
 
-	fmt.Printf("🔧 Running task: %s\n", taskName)
+	if !holds {
+		r.Ran[taskName] = true
+		r.Timings = append(r.Timings, TaskTiming{Task: taskName, Skipped: true, WhenFalse: true})
+		r.mutex.Unlock()
+		fmt.Fprintf(r.taskOut(taskName), "⏭️  Task %s skipped (when=false)\n", taskName)
+		return nil
+	}
 
 	// Prompt for interactive input if needed
-	interactiveInputs, err := r.promptForInput(taskName, task)
+	interactiveInputs, err := r.promptForInput(taskName, task, vars)
 	if err != nil {
 		r.mutex.Unlock()
 		return fmt.Errorf("interactive input failed: %w", err)
 	}
 
+	// Ask for confirmation before running dangerous tasks
+	if err := r.confirmTask(taskName, task, vars, interactiveInputs); err != nil {
+		r.mutex.Unlock()
+		return err
+	}
+
 	// Mark as running to prevent duplicate execution
 	r.Ran[taskName] = true
+	run := int(lastReportRun.Add(1))
+	if r.reportRuns == nil {
+		r.reportRuns = make(map[string]int)
+	}
+	r.reportRuns[taskName] = run
 	r.mutex.Unlock()
 
+	if task.Lock.Enabled {
+		lock, err := r.acquireTaskLock(taskName, task, path)
+		if err != nil {
+			return err
+		}
+		defer lock.release()
+	}
+
 	// Run task commands sequentially (commands within a task should be sequential)
-	return r.executeCommandsWithInteractive(taskName, task.Cmds, interactiveInputs)
+	lane := r.Trace.beginTask(taskName)
+	for _, reporter := range r.reporters() {
+		reporter.OnTaskStart(run, taskName)
+	}
+	start := time.Now()
+	err = r.runGlobalHooks(taskName, task, vars, interactiveInputs, path, func() error {
+		return r.runWithHooks(taskName, task.Before, task.After, vars, interactiveInputs, path, func() error {
+			if len(task.Matrix) > 0 {
+				return r.runMatrix(taskName, task, vars, interactiveInputs, path)
+			}
+			return r.runTaskCmds(taskName, task, vars, interactiveInputs, path)
+		})
+	})
+
+	r.mutex.Lock()
+	r.Timings = append(r.Timings, TaskTiming{
+		Task:     taskName,
+		Duration: time.Since(start),
+		Failed:   err != nil,
+	})
+	r.mutex.Unlock()
+
+	traceArgs := map[string]string{"status": traceStatus(err)}
+	if len(path) > 1 {
+		traceArgs["parent"] = path[len(path)-2]
+	}
+	r.Trace.addSpan(taskName, "task", lane, start, traceArgs)
+	for _, reporter := range r.reporters() {
+		reporter.OnTaskEnd(run, taskName, time.Since(start), err)
+	}
+
+	return err
+}
+
+// recordSkipped notes that a task was requested again after it already ran
+func (r *Runner) recordSkipped(taskName string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, timing := range r.Timings {
+		if timing.Task == taskName && timing.Skipped {
+			return
+		}
+	}
+	r.Timings = append(r.Timings, TaskTiming{Task: taskName, Skipped: true})
 }
 
 // runDependenciesParallel runs dependencies in parallel where possible
-func (r *Runner) runDependenciesParallel(deps []string) error {
+func (r *Runner) runDependenciesParallel(deps []Dep, path []string) error {
 	if len(deps) == 1 {
 		// Single dependency - run directly
-		return r.runTaskWithSync(deps[0])
+		return r.allowFailure(deps[0], r.runTaskWithSync(deps[0].Task, path, deps[0].Always), path)
 	}
 
 	// Multiple dependencies - run in parallel
 	var wg sync.WaitGroup
 	errChan := make(chan error, len(deps))
 
+	// Groups are printed where the output of the task needing them goes
+	out := r.pathOut(path)
+
 	for _, dep := range deps {
+		flush := r.separateOutput(dep.Task, out)
 		wg.Add(1)
-		go func(depName string) {
+		go func(dep Dep) {
 			defer wg.Done()
-			if err := r.runTaskWithSync(depName); err != nil {
-				errChan <- fmt.Errorf("dependency %s failed: %w", depName, err)
+			err := r.runTaskWithSync(dep.Task, path, dep.Always)
+			flush()
+			if err = r.allowFailure(dep, err, path); err != nil {
+				errChan <- fmt.Errorf("dependency %s failed: %w", dep.Task, err)
 			}
 		}(dep)
 	}
@@ -169,9 +660,46 @@ func (r *Runner) runDependenciesParallel(deps []string) error {
 	wg.Wait()
 	close(errChan)
 
-	// Check for any errors
-	for err := range errChan {
-		if err != nil {
+	// Collect every failure when continuing after errors
+	if r.ContinueOnError {
+		var errs []error
+		for err := range errChan {
+			errs = append(errs, err)
+		}
+		if len(errs) > 0 {
+			return fmt.Errorf("%d of %d dependencies failed:\n%w", len(errs), len(deps), errors.Join(errs...))
+		}
+		return nil
+	}
+
+	// Report the first failure, mentioning any others so they aren't missed
+	first, ok := <-errChan
+	if !ok {
+		return nil
+	}
+	if others := len(errChan); others > 0 {
+		return fmt.Errorf("%w (%d more dependency(s) failed, use --fail-fast=false to see all)", first, others)
+	}
+	return first
+}
+
+// executeCommandsWithInteractive runs the commands for a task sequentially with interactive inputs
+func (r *Runner) executeCommandsWithInteractive(taskName string, commands []Command, vars map[string]string, interactiveInputs map[string]string, path []string) error {
+	taskCtx, taskTimeout, cancel, err := r.taskContext(taskName)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	// Captured output is only visible to the rest of this command list
+	inputs := make(map[string]string, len(interactiveInputs))
+	for name, value := range interactiveInputs {
+		inputs[name] = value
+	}
+	interactiveInputs = inputs
+
+	for _, command := range commands {
+		if err := r.runCommand(taskCtx, taskTimeout, taskName, command, "", vars, interactiveInputs, path); err != nil {
 			return err
 		}
 	}
@@ -179,82 +707,431 @@ func (r *Runner) runDependenciesParallel(deps []string) error {
 	return nil
 }
 
-// executeCommands runs the commands for a task sequentially
-func (r *Runner) executeCommands(taskName string, commands []string) error {
-	for _, rawCmd := range commands {
-		cmdStr, err := r.expandVars(rawCmd)
-		if err != nil {
-			return err
+// executeCommandsParallel runs the commands of a task with parallel: true
+// concurrently, at most Runner.Parallel at a time when it is set. Output is
+// prefixed with the command's position unless the command has its own
+// prefix. Every command runs and all failures are reported.
+func (r *Runner) executeCommandsParallel(taskName string, commands []Command, vars map[string]string, interactiveInputs map[string]string, path []string) error {
+	taskCtx, taskTimeout, cancel, err := r.taskContext(taskName)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	for _, command := range commands {
+		if command.Capture != "" {
+			return fmt.Errorf("task %s: capture can't be used in a parallel task, there is no later command to use $%s", taskName, command.Capture)
 		}
+	}
 
-		fmt.Printf("➡️  %s\n", cmdStr)
+	limit := r.Parallel
+	if limit <= 0 {
+		limit = len(commands)
+	}
 
-		var cmd *exec.Cmd
-		if runtime.GOOS == "windows" {
-			cmd = exec.Command("powershell", "-Command", cmdStr)
-		} else {
-			cmd = exec.Command("sh", "-c", cmdStr)
+	errs := make([]error, len(commands))
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, limit)
+	for i, command := range commands {
+		wg.Add(1)
+		go func(i int, command Command) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			errs[i] = r.runCommand(taskCtx, taskTimeout, taskName, command, strconv.Itoa(i+1), vars, interactiveInputs, path)
+		}(i, command)
+	}
+	wg.Wait()
+
+	var failed []error
+	for _, err := range errs {
+		if err != nil {
+			failed = append(failed, err)
 		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%d of %d parallel commands failed:\n%w", len(failed), len(commands), errors.Join(failed...))
+	}
 
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
-		cmd.Stdin = os.Stdin
+	return nil
+}
+
+// runTaskCmds runs a task's cmds list, concurrently when the task sets
+// parallel: true
+func (r *Runner) runTaskCmds(taskName string, task Task, vars map[string]string, interactiveInputs map[string]string, path []string) error {
+	if task.Parallel {
+		return r.executeCommandsParallel(taskName, task.Cmds, vars, interactiveInputs, path)
+	}
+	return r.executeCommandsWithInteractive(taskName, task.Cmds, vars, interactiveInputs, path)
+}
 
-		if err := cmd.Run(); err != nil {
-			return fmt.Errorf("command failed: %s", cmdStr)
+// taskContext returns the context that bounds a task's whole command list
+// by its timeout, and that timeout. Tasks without a timeout get Timeout or
+// else the config's.
+func (r *Runner) taskContext(taskName string) (context.Context, time.Duration, context.CancelFunc, error) {
+	taskTimeout, err := parseTimeout(r.Config.Tasks[taskName].Timeout)
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("task %s: %w", taskName, err)
+	}
+	if taskTimeout == 0 {
+		taskTimeout = r.Timeout
+	}
+	if taskTimeout == 0 {
+		if taskTimeout, err = parseTimeout(r.Config.Timeout); err != nil {
+			return nil, 0, nil, fmt.Errorf("timeout: %w", err)
 		}
+	}
+	if taskTimeout > 0 {
+		ctx, cancel := context.WithTimeout(r.context(), taskTimeout)
+		return ctx, taskTimeout, cancel, nil
+	}
+	return r.context(), 0, func() {}, nil
+}
 
-		fmt.Printf("✅ done\n")
+// context returns the context every command of the run derives from
+func (r *Runner) context() context.Context {
+	if r.Context == nil {
+		return context.Background()
 	}
+	return r.Context
+}
 
-	return nil
+// checkBudget fails once the run's context is done, so that no new task or
+// command starts after --max-runtime
+func (r *Runner) checkBudget() error {
+	if errors.Is(r.context().Err(), context.DeadlineExceeded) {
+		return ErrMaxRuntime
+	}
+	return r.context().Err()
 }
 
-// executeCommandsWithInteractive runs the commands for a task sequentially with interactive inputs
-func (r *Runner) executeCommandsWithInteractive(taskName string, commands []string, interactiveInputs map[string]string) error {
-	for _, rawCmd := range commands {
-		// First expand regular variables
-		cmdStr, err := r.expandVars(rawCmd)
-		if err != nil {
-			return err
+// runCommand runs a single entry of a command list. label marks the output of
+// commands running in parallel and is empty otherwise.
+func (r *Runner) runCommand(taskCtx context.Context, taskTimeout time.Duration, taskName string, command Command, label string, vars map[string]string, interactiveInputs map[string]string, path []string) error {
+	// Task references run inline at this position in the command list
+	if command.Task != "" {
+		return r.runTaskCommand(command, vars, path)
+	}
+
+	// A command timeout nests inside the task timeout, so the stricter wins
+	cmdTimeout, err := parseTimeout(command.Timeout)
+	if err != nil {
+		return fmt.Errorf("task %s: %w", taskName, err)
+	}
+	ctx, cancel := taskCtx, context.CancelFunc(func() {})
+	if cmdTimeout > 0 {
+		ctx, cancel = context.WithTimeout(taskCtx, cmdTimeout)
+	}
+	defer cancel()
+
+	// The idle timeout only fires while the command stays silent
+	idleTimeout := r.IdleTimeout
+	if value := r.Config.Tasks[taskName].IdleTimeout; value != "" {
+		if idleTimeout, err = parseTimeout(value); err != nil {
+			return fmt.Errorf("task %s: idle_timeout: %w", taskName, err)
 		}
+	}
+	var watchdog *idleWatchdog
+	if idleTimeout > 0 {
+		var stopWatchdog context.CancelFunc
+		watchdog, ctx, stopWatchdog = newIdleWatchdog(ctx, idleTimeout)
+		defer stopWatchdog()
+	}
 
-		// Then expand interactive variables
-		cmdStr, err = r.expandVarsWithInteractive(cmdStr, interactiveInputs)
-		if err != nil {
-			return err
+	cmd, cmdStr, err := r.newCommand(ctx, r.Config.Tasks[taskName], command, vars, interactiveInputs)
+	if err != nil {
+		return fmt.Errorf("task %s: %w", taskName, err)
+	}
+	// A command left empty by a template condition, such as an after hook
+	// only meant for failures, has nothing to run
+	if len(command.Exec) == 0 && command.Capture == "" && strings.TrimSpace(cmdStr) == "" {
+		return nil
+	}
+	// A missing dir would otherwise only show as a failed command
+	if command.Dir != "" {
+		if info, err := os.Stat(cmd.Dir); err != nil || !info.IsDir() {
+			return fmt.Errorf("task %s: dir %s doesn't exist (%s)", taskName, command.Dir, r.commandLocation(command))
 		}
+	}
 
-		fmt.Printf("➡️  %s\n", cmdStr)
+	// Output of dependencies running side by side is marked with their name
+	depPrefix := ""
+	if label == "" {
+		depPrefix = r.outputPrefix(taskName)
+	}
+	markLabel := label
+	if markLabel == "" {
+		markLabel = depPrefix
+	}
+	marker := ""
+	if markLabel != "" {
+		marker = "[" + markLabel + "] "
+	}
+	if command.ShouldEcho() {
+		fmt.Fprintf(r.taskOut(taskName), "➡️  %s%s\n", marker, cmdStr)
+	}
 
-		var cmd *exec.Cmd
-		if runtime.GOOS == "windows" {
-			cmd = exec.Command("powershell", "-Command", cmdStr)
-		} else {
-			cmd = exec.Command("sh", "-c", cmdStr)
-		}
+	stdin, err := r.commandStdin(command, vars, interactiveInputs)
+	if err != nil {
+		return fmt.Errorf("task %s: %w", taskName, err)
+	}
+	defer closeStdin(stdin)
 
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
+	cmd.Stdout = r.taskCommandOut(taskName)
+	cmd.Stderr = r.taskErrOut(taskName)
+	// Parallel commands can't share the terminal, so they get the null device
+	if label == "" {
 		cmd.Stdin = os.Stdin
+	}
+	if stdin != nil {
+		cmd.Stdin = stdin
+	}
+	r.setCommandEnv(cmd)
+	r.setCommandCancel(cmd)
+	// Don't wait forever for output from children that outlive a killed command
+	cmd.WaitDelay = time.Second
+
+	// Prefix each output line so interleaved output stays attributable
+	prefix, color := command.Prefix, ""
+	if prefix == "" {
+		prefix = label
+	}
+	if prefix == "" && depPrefix != "" {
+		prefix, color = depPrefix, r.prefixColor(depPrefix)
+	}
+	var stdout, stderr *prefixWriter
+	if prefix != "" {
+		stdout = newPrefixWriter(cmd.Stdout, prefix, color)
+		stderr = newPrefixWriter(cmd.Stderr, prefix, color)
+		cmd.Stdout = stdout
+		cmd.Stderr = stderr
+	}
+	// Secrets are masked before lines are prefixed, but not in captured output
+	stdoutMask, stderrMask := NewMaskingStream(cmd.Stdout), NewMaskingStream(cmd.Stderr)
+	cmd.Stdout, cmd.Stderr = stdoutMask, stderrMask
+	var captured bytes.Buffer
+	if command.Capture != "" {
+		cmd.Stdout = &captured
+	}
+	if watchdog != nil {
+		cmd.Stdout = watchdog.writer(cmd.Stdout)
+		cmd.Stderr = watchdog.writer(cmd.Stderr)
+	}
 
-		if err := cmd.Run(); err != nil {
-			return fmt.Errorf("command failed: %s", cmdStr)
+	cmdStart := time.Now()
+	err = cmd.Run()
+	r.Trace.addSpan(cmdStr, "command", r.Trace.lane(taskName), cmdStart, map[string]string{
+		"task":   taskName,
+		"status": traceStatus(err),
+	})
+	duration := time.Since(cmdStart)
+	stdoutMask.Flush()
+	stderrMask.Flush()
+	if stdout != nil {
+		stdout.Flush()
+		stderr.Flush()
+	}
+	// Reported once its output is out, so "done" comes after it
+	for _, reporter := range r.reporters() {
+		reporter.OnCommandEnd(r.reportRun(taskName), taskName, markLabel, cmdStr, duration, err)
+	}
+	if err != nil {
+		failure := newTaskFailedError(taskName, cmdStr, err)
+		failure.Location = r.commandLocation(command)
+		if budgetErr := r.checkBudget(); budgetErr != nil {
+			return fmt.Errorf("%w: %w", budgetErr, failure)
 		}
+		if errors.Is(taskCtx.Err(), context.DeadlineExceeded) {
+			failure.Timeout = taskTimeout
+		} else if watchdog != nil && watchdog.Fired() {
+			failure.Timeout = idleTimeout
+			failure.Idle = true
+		} else if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			failure.Timeout = cmdTimeout
+		}
+		return failure
+	}
+
+	// Like $(...) in a shell, trailing newlines are dropped and inner ones kept
+	if command.Capture != "" {
+		interactiveInputs[command.Capture] = strings.TrimRight(captured.String(), "\r\n")
+	}
+	return nil
+}
+
+// lastReportRun numbers the runs of tasks for reporters, across the runners
+// of repeated runs sharing one
+var lastReportRun atomic.Int64
+
+// reporters returns the reporters to tell of a run: the console reporter
+// printing progress and Reporter when set
+func (r *Runner) reporters() []Reporter {
+	reporters := []Reporter{&ConsoleReporter{Out: r.taskOut}}
+	if r.Reporter != nil {
+		reporters = append(reporters, r.Reporter)
+	}
+	return reporters
+}
+
+// reportRun returns the number of the latest run of a task
+func (r *Runner) reportRun(taskName string) int {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.reportRuns[taskName]
+}
+
+// parseTimeout parses a timeout such as "10s" or "2m". An empty string means
+// no timeout.
+func parseTimeout(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout <= 0 {
+		return 0, fmt.Errorf("invalid timeout %q", value)
+	}
+	return timeout, nil
+}
+
+// commandLocation returns where a command is written, like tasks.yaml:42,
+// or "" when that isn't known
+func (r *Runner) commandLocation(command Command) string {
+	source := command.Source
+	if source == "" {
+		source = r.ConfigSource
+	}
+	switch {
+	case command.SourceLine == 0:
+		return ""
+	case source == "":
+		return fmt.Sprintf("line %d", command.SourceLine)
+	default:
+		return fmt.Sprintf("%s:%d", source, command.SourceLine)
+	}
+}
+
+// runTaskCommand runs a task referenced from a command list, reusing the
+// memoization so a task that already ran is not executed again
+func (r *Runner) runTaskCommand(command Command, vars map[string]string, path []string) error {
+	name, err := r.expandVars(command.Task, vars)
+	if err != nil {
+		return err
+	}
 
-		fmt.Printf("✅ done\n")
+	if err := r.runTaskWithSync(name, path, false); err != nil {
+		return fmt.Errorf("task %s failed: %w", name, err)
 	}
 
 	return nil
-} // expandVars replaces variables in commands with their values
-func (r *Runner) expandVars(command string) (string, error) {
-	tmpl, err := template.New("cmd").Parse(command)
+}
+
+// EffectiveVars returns the variables available to a task's commands: the
+// global vars overlaid by the task's own vars
+func (r *Runner) EffectiveVars(taskName string) (map[string]string, error) {
+	task, exists := r.Config.Tasks[taskName]
+	if !exists {
+		return nil, r.taskNotFound(taskName)
+	}
+
+	return r.taskVars(taskName, task)
+}
+
+// taskVars merges the task's vars over the global vars and Env. Task var
+// values are expanded against those so they can build on them. The built-in
+// vars from builtinVars are reserved and always win.
+func (r *Runner) taskVars(taskName string, task Task) (map[string]string, error) {
+	builtins := builtinVars(taskName, task)
+
+	globals := make(map[string]string, len(r.Config.Vars)+len(r.Env)+len(builtins))
+	for name, value := range r.Config.Vars {
+		globals[name] = value
+	}
+	for name, value := range r.Env {
+		globals[name] = value
+	}
+	for name, value := range builtins {
+		globals[name] = value
+	}
+
+	vars := make(map[string]string, len(globals)+len(task.Vars))
+	for name, value := range globals {
+		vars[name] = value
+	}
+
+	for name, value := range task.Vars {
+		if _, reserved := builtins[name]; reserved {
+			continue
+		}
+		expanded, err := r.expandVars(value, globals)
+		if err != nil {
+			return nil, fmt.Errorf("var %s: %w", name, err)
+		}
+		vars[name] = expanded
+	}
+
+	return vars, nil
+}
+
+// IsBuiltinVar reports whether name is one of the reserved built-in vars
+func IsBuiltinVar(name string) bool {
+	_, ok := builtinVars("", Task{})[name]
+	return ok
+}
+
+// builtinVars returns the variables every task gets without declaring them
+func builtinVars(taskName string, task Task) map[string]string {
+	return map[string]string{
+		"TASK":      taskName,
+		"TASK_DESC": task.Desc,
+		"NUM_DEPS":  strconv.Itoa(len(task.Deps.Names())),
+		"OS":        runtime.GOOS,
+		"ARCH":      runtime.GOARCH,
+	}
+}
+
+// expandDeps expands variables in dependency names, stage by stage. A dep
+// that expands to an empty string is skipped and one that expands to a
+// comma-separated list is split into several deps of the same stage. Stages
+// left empty are dropped. Unset vars expand to an empty string. The deps of
+// a task with deps_always are all marked always.
+func (r *Runner) expandDeps(task Task, vars map[string]string) ([][]Dep, error) {
+	var stages [][]Dep
+	for _, stage := range task.Deps {
+		var expanded []Dep
+		for _, dep := range stage {
+			tmpl, err := template.New("dep").Option("missingkey=zero").Funcs(r.templateFuncs()).Parse(dep.Task)
+			if err != nil {
+				return nil, fmt.Errorf("dep %q: %w", dep.Task, err)
+			}
+
+			var buf bytes.Buffer
+			if err := tmpl.Execute(&buf, vars); err != nil {
+				return nil, fmt.Errorf("dep %q: %w", dep.Task, err)
+			}
+
+			for _, name := range strings.Split(buf.String(), ",") {
+				if name = strings.TrimSpace(name); name != "" {
+					expanded = append(expanded, Dep{Task: name, AllowFailure: dep.AllowFailure, Always: dep.Always || task.DepsAlways})
+				}
+			}
+		}
+		if len(expanded) > 0 {
+			stages = append(stages, expanded)
+		}
+	}
+	return stages, nil
+}
+
+// expandVars replaces variables in commands with their values
+func (r *Runner) expandVars(command string, vars map[string]string) (string, error) {
+	tmpl, err := template.New("cmd").Funcs(r.templateFuncs()).Parse(command)
 	if err != nil {
 		return "", err
 	}
 
 	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, r.Config.Vars); err != nil {
+	if err := tmpl.Execute(&buf, vars); err != nil {
 		return "", err
 	}
 
@@ -262,32 +1139,52 @@ func (r *Runner) expandVars(command string) (string, error) {
 }
 
 // promptForInput prompts the user for interactive input
-func (r *Runner) promptForInput(taskName string, task Task) (map[string]string, error) {
+func (r *Runner) promptForInput(taskName string, task Task, vars map[string]string) (map[string]string, error) {
 	inputs := make(map[string]string)
 
 	if len(task.Interactive) == 0 {
 		return inputs, nil
 	}
 
-	fmt.Printf("🤔 Task '%s' requires interactive input:\n\n", taskName)
+	if r.NonInteractive {
+		names := task.InteractiveNames()
+		for i, name := range names {
+			names[i] = "$" + name
+		}
+		return nil, fmt.Errorf("%w: task %s asks for %s", ErrInputDisabled, taskName, strings.Join(names, ", "))
+	}
+
+	fmt.Fprintf(r.Out, "🤔 Task '%s' requires interactive input:\n\n", taskName)
+
+	reader := r.stdinReader()
 
-	reader := bufio.NewReader(os.Stdin)
+	for _, varName := range task.InteractiveNames() {
+		prompt := task.Interactive[varName]
+
+		// Messages and defaults can use the answers given so far
+		message, err := r.expandPrompt(prompt.Message, task, vars, inputs)
+		if err != nil {
+			return nil, err
+		}
+		defaultValue, err := r.expandPrompt(prompt.Default, task, vars, inputs)
+		if err != nil {
+			return nil, err
+		}
 
-	for varName, prompt := range task.Interactive {
 		// Show the prompt message
-		fmt.Printf("📝 %s", prompt.Message)
+		fmt.Fprintf(r.Out, "📝 %s", message)
 
 		// Show default value if available
-		if prompt.Default != "" {
-			fmt.Printf(" [%s]", prompt.Default)
+		if defaultValue != "" {
+			fmt.Fprintf(r.Out, " [%s]", defaultValue)
 		}
 
 		// Show required indicator
 		if prompt.Required {
-			fmt.Printf(" (required)")
+			fmt.Fprintf(r.Out, " (required)")
 		}
 
-		fmt.Print(": ")
+		fmt.Fprint(r.Out, ": ")
 
 		// Read user input
 		input, err := reader.ReadString('\n')
@@ -299,8 +1196,8 @@ func (r *Runner) promptForInput(taskName string, task Task) (map[string]string,
 		input = strings.TrimSpace(input)
 
 		// Use default if no input provided
-		if input == "" && prompt.Default != "" {
-			input = prompt.Default
+		if input == "" && defaultValue != "" {
+			input = defaultValue
 		}
 
 		// Check if required input is provided
@@ -309,47 +1206,175 @@ func (r *Runner) promptForInput(taskName string, task Task) (map[string]string,
 		}
 
 		inputs[varName] = input
-		fmt.Printf("✅ %s: %s\n", varName, input)
+		fmt.Fprintf(r.Out, "✅ %s: %s\n", varName, input)
 	}
 
-	fmt.Println()
+	fmt.Fprintln(r.Out)
 	return inputs, nil
 }
 
+// stdinReader returns the reader shared by all prompts so buffered input is
+// not lost between them
+func (r *Runner) stdinReader() *bufio.Reader {
+	r.inputOnce.Do(func() {
+		if r.input == nil {
+			r.input = bufio.NewReader(os.Stdin)
+		}
+	})
+	return r.input
+}
+
+// confirmTask asks the user to confirm a task marked with `confirm` before it
+// runs. The message is expanded with the task's vars and interactive inputs.
+func (r *Runner) confirmTask(taskName string, task Task, vars map[string]string, interactiveInputs map[string]string) error {
+	if !task.Confirm.Enabled || r.AssumeYes {
+		return nil
+	}
+
+	message := task.Confirm.Message
+	if message == "" {
+		message = fmt.Sprintf("Are you sure you want to run task '%s'?", taskName)
+	}
+
+	message, err := r.expandVars(message, vars)
+	if err != nil {
+		return err
+	}
+	message, err = r.expandVarsWithInteractive(message, interactiveInputs)
+	if err != nil {
+		return err
+	}
+
+	if r.NonInteractive {
+		return fmt.Errorf("%w: task %s requires confirmation, pass --yes to run it", ErrInputDisabled, taskName)
+	}
+
+	// Refuse to guess when nobody can answer the prompt
+	if stat, err := os.Stdin.Stat(); err != nil || stat.Mode()&os.ModeCharDevice == 0 {
+		return fmt.Errorf("task %s requires confirmation, pass --yes to run it non-interactively", taskName)
+	}
+
+	fmt.Fprintf(r.Out, "⚠️  %s [y/N]: ", message)
+	answer, err := r.stdinReader().ReadString('\n')
+	if err == io.EOF {
+		fmt.Fprintln(r.Out)
+		return fmt.Errorf("task %s requires confirmation, pass --yes to run it non-interactively", taskName)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read confirmation: %w", err)
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return nil
+	default:
+		return fmt.Errorf("task %s was not confirmed", taskName)
+	}
+}
+
 // expandVarsWithInteractive replaces variables in commands with their values including interactive inputs
 func (r *Runner) expandVarsWithInteractive(cmdStr string, interactiveInputs map[string]string) (string, error) {
-	result := cmdStr
+	if len(interactiveInputs) == 0 {
+		return cmdStr, nil
+	}
 
-	// Expand interactive variables using $variable syntax
-	for varName, value := range interactiveInputs {
-		result = strings.ReplaceAll(result, "$"+varName, value)
+	// Longer names go first so $files isn't read as $file followed by "s"
+	names := make([]string, 0, len(interactiveInputs))
+	for varName := range interactiveInputs {
+		names = append(names, regexp.QuoteMeta(varName))
 	}
+	sort.Slice(names, func(i, j int) bool {
+		if len(names[i]) != len(names[j]) {
+			return len(names[i]) > len(names[j])
+		}
+		return names[i] < names[j]
+	})
 
-	return result, nil
-} // RunTaskDetached runs a task in the background and returns immediately
+	// Expand interactive variables using $variable syntax in a single pass,
+	// so a value that contains $other is never expanded again
+	pattern, err := regexp.Compile(`\$(` + strings.Join(names, "|") + `)`)
+	if err != nil {
+		return "", err
+	}
+	return pattern.ReplaceAllStringFunc(cmdStr, func(match string) string {
+		return interactiveInputs[match[1:]]
+	}), nil
+}
+
+// RunTaskDetached runs a task in the background and returns immediately.
+// It returns no process when the task's when condition doesn't hold.
 func (r *Runner) RunTaskDetached(taskName string) (*DetachedProcess, error) {
 	task, exists := r.Config.Tasks[taskName]
 	if !exists {
-		return nil, fmt.Errorf("task %s not found", taskName)
+		return nil, r.taskNotFound(taskName)
+	}
+
+	if err := checkRequires(taskName, task); err != nil {
+		return nil, err
+	}
+	if len(task.Matrix) > 0 {
+		return nil, fmt.Errorf("task %s has a matrix, which can't run detached", taskName)
+	}
+
+	// A second instance usually fights the first one over a port or a file
+	if !r.AllowDuplicate {
+		processes, err := r.ListDetachedProcesses()
+		if err != nil {
+			return nil, err
+		}
+		for _, proc := range processes {
+			if proc.TaskName == taskName && proc.ScheduledAt == nil {
+				return nil, fmt.Errorf("%w: %s (PID %d)", ErrAlreadyRunning, taskName, proc.PID)
+			}
+		}
+	}
+
+	vars, err := r.taskVars(taskName, task)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expand vars of task %s: %w", taskName, err)
+	}
+
+	if r.DetachedDryRun {
+		return r.planDetached(taskName, task, vars)
+	}
+
+	if err := r.confirmTask(taskName, task, vars, nil); err != nil {
+		return nil, err
+	}
+
+	deps, err := r.expandDeps(task, vars)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expand deps of task %s: %w", taskName, err)
 	}
 
 	// Run dependencies first (synchronously)
-	if len(task.Deps) > 0 {
-		fmt.Printf("🔧 Running dependencies for detached task: %s\n", taskName)
-		if err := r.runDependenciesParallel(task.Deps); err != nil {
+	if len(deps) > 0 {
+		fmt.Fprintf(r.Out, "🔧 Running dependencies for detached task: %s\n", taskName)
+		if err := r.runDependencies(deps, []string{taskName}); err != nil {
 			return nil, fmt.Errorf("dependencies failed: %w", err)
 		}
 	}
 
+	// As in the foreground, the condition is checked once the deps ran
+	if task.When != "" {
+		holds, err := r.checkWhen(taskName, task, vars)
+		if err != nil {
+			return nil, fmt.Errorf("when of task %s: %w", taskName, err)
+		}
+		if !holds {
+			fmt.Fprintf(r.Out, "⏭️  Task %s skipped (when=false)\n", taskName)
+			return nil, nil
+		}
+	}
+
 	// Create logs directory if it doesn't exist
-	logsDir := ".t-logs"
+	logsDir := r.LogsDir()
 	if err := os.MkdirAll(logsDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create logs directory: %w", err)
 	}
 
 	// Create log file for this task
-	timestamp := time.Now().Format("20060102-150405")
-	logFile := filepath.Join(logsDir, fmt.Sprintf("%s-%s.log", taskName, timestamp))
+	logFile := r.detachedLogFile(taskName)
 
 	// Start the first command in detached mode
 	if len(task.Cmds) == 0 {
@@ -361,48 +1386,105 @@ func (r *Runner) RunTaskDetached(taskName string) (*DetachedProcess, error) {
 	mainCmd := task.Cmds[len(task.Cmds)-1]    // Use last command as main
 	setupCmds := task.Cmds[:len(task.Cmds)-1] // Previous commands as setup
 
+	// The lock is taken before the setup commands and handed on to the
+	// process where the platform allows it
+	var lock *taskLock
+	if task.Lock.Enabled {
+		if lock, err = r.acquireTaskLock(taskName, task, []string{taskName}); err != nil {
+			return nil, err
+		}
+		defer func() { lock.release() }()
+	}
+
+	if err := r.runDetachedHooks(taskName, task, vars); err != nil {
+		return nil, err
+	}
+
 	// Run setup commands first (if any)
+	var steps []string
 	if len(setupCmds) > 0 {
-		fmt.Printf("🔧 Running setup commands for detached task: %s\n", taskName)
-		for _, rawCmd := range setupCmds {
-			cmdStr, err := r.expandVars(rawCmd)
+		fmt.Fprintf(r.Out, "🔧 Running setup commands for detached task: %s\n", taskName)
+		for _, command := range setupCmds {
+			if command.Task != "" {
+				if err := r.runTaskCommand(command, vars, []string{taskName}); err != nil {
+					return nil, err
+				}
+				name, _ := r.expandVars(command.Task, vars)
+				steps = append(steps, "task: "+name)
+				continue
+			}
+
+			cmd, cmdStr, err := r.newCommand(r.context(), task, command, vars, nil)
 			if err != nil {
 				return nil, err
 			}
+			steps = append(steps, cmdStr)
 
-			fmt.Printf("➡️  %s\n", cmdStr)
-			var cmd *exec.Cmd
-			if runtime.GOOS == "windows" {
-				cmd = exec.Command("powershell", "-Command", cmdStr)
-			} else {
-				cmd = exec.Command("sh", "-c", cmdStr)
+			if command.ShouldEcho() {
+				fmt.Fprintf(r.Out, "➡️  %s\n", cmdStr)
 			}
+			cmd.WaitDelay = time.Second
 
-			cmd.Stdout = os.Stdout
-			cmd.Stderr = os.Stderr
+			stdin, err := r.commandStdin(command, vars, nil)
+			if err != nil {
+				return nil, fmt.Errorf("task %s: %w", taskName, err)
+			}
 
-			if err := cmd.Run(); err != nil {
-				return nil, fmt.Errorf("setup command failed: %s", cmdStr)
+			cmd.Stdout = r.commandOut()
+			cmd.Stderr = r.ErrOut
+			cmd.Stdin = stdin
+			r.setCommandEnv(cmd)
+			r.setCommandCancel(cmd)
+
+			var stdout, stderr *prefixWriter
+			if command.Prefix != "" {
+				stdout = newPrefixWriter(r.commandOut(), command.Prefix, "")
+				stderr = newPrefixWriter(r.ErrOut, command.Prefix, "")
+				cmd.Stdout = stdout
+				cmd.Stderr = stderr
+			}
+			stdoutMask, stderrMask := NewMaskingStream(cmd.Stdout), NewMaskingStream(cmd.Stderr)
+			cmd.Stdout, cmd.Stderr = stdoutMask, stderrMask
+
+			err = cmd.Run()
+			closeStdin(stdin)
+			stdoutMask.Flush()
+			stderrMask.Flush()
+			if stdout != nil {
+				stdout.Flush()
+				stderr.Flush()
+			}
+			if err != nil {
+				setupErr := newTaskFailedError(taskName, cmdStr, err)
+				setupErr.Location = r.commandLocation(command)
+				failure := fmt.Errorf("setup %w", setupErr)
+				if budgetErr := r.checkBudget(); budgetErr != nil {
+					return nil, fmt.Errorf("%w: %w", budgetErr, failure)
+				}
+				return nil, failure
 			}
-			fmt.Printf("✅ done\n")
+			fmt.Fprintf(r.Out, "✅ done\n")
 		}
 	}
 
-	// Expand variables in the main command
-	cmdStr, err := r.expandVars(mainCmd)
+	if mainCmd.Task != "" {
+		return nil, fmt.Errorf("the last command of detached task %s must be a shell command, not a task reference", taskName)
+	}
+
+	if err := r.checkBudget(); err != nil {
+		return nil, fmt.Errorf("%w: detached task %s not started", err, taskName)
+	}
+
+	// Expand variables in the main command. It outlives t, so it isn't
+	// bound to the run's context
+	cmd, cmdStr, err := r.newCommand(context.Background(), task, mainCmd, vars, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	fmt.Printf("🚀 Starting detached task: %s\n", taskName)
-	fmt.Printf("➡️  %s\n", cmdStr)
-
-	// Create the command
-	var cmd *exec.Cmd
-	if runtime.GOOS == "windows" {
-		cmd = exec.Command("powershell", "-Command", cmdStr)
-	} else {
-		cmd = exec.Command("sh", "-c", cmdStr)
+	fmt.Fprintf(r.Out, "🚀 Starting detached task: %s\n", taskName)
+	if mainCmd.ShouldEcho() {
+		fmt.Fprintf(r.Out, "➡️  %s\n", cmdStr)
 	}
 
 	// Create or open log file
@@ -411,45 +1493,105 @@ func (r *Runner) RunTaskDetached(taskName string) (*DetachedProcess, error) {
 		return nil, fmt.Errorf("failed to create log file: %w", err)
 	}
 
-	// Redirect output to log file
+	// Redirect output to log file. The file is handed to the process directly
+	// (no prefixing) so it keeps logging after t exits
 	cmd.Stdout = logFileHandle
 	cmd.Stderr = logFileHandle
+	r.setCommandEnv(cmd)
+
+	// A log writer sits between the process and the log file as a separate
+	// process, so it also outlives t. Its own errors go to the file as it
+	// was first created.
+	logWriterPID := 0
+	if len(r.DetachedLogWriter) > 0 {
+		pipeReader, pipeWriter, err := os.Pipe()
+		if err != nil {
+			logFileHandle.Close()
+			return nil, fmt.Errorf("failed to create log pipe: %w", err)
+		}
+		defer pipeReader.Close()
+		defer pipeWriter.Close()
+
+		writerArgs := append(r.DetachedLogWriter[1:len(r.DetachedLogWriter):len(r.DetachedLogWriter)], logFile)
+		writer := exec.Command(r.DetachedLogWriter[0], writerArgs...)
+		writer.Stdin = pipeReader
+		writer.Stdout = logFileHandle
+		writer.Stderr = logFileHandle
+		setProcessGroup(writer)
+		if err := writer.Start(); err != nil {
+			logFileHandle.Close()
+			return nil, fmt.Errorf("failed to start log writer: %w", err)
+		}
+		logWriterPID = writer.Process.Pid
+		go writer.Wait()
+
+		// Secrets are handed to the writer alone, which masks them in the
+		// log. They come first on the pipe, ahead of anything the task prints.
+		if err := writeLogSecrets(pipeWriter); err != nil {
+			logFileHandle.Close()
+			return nil, fmt.Errorf("failed to hand secrets to the log writer: %w", err)
+		}
 
-	// Set up process group for proper cleanup of child processes
-	if runtime.GOOS == "windows" {
-		// On Windows, create a new process group
-		cmd.SysProcAttr = &syscall.SysProcAttr{
-			CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP,
+		cmd.Stdout = pipeWriter
+		cmd.Stderr = pipeWriter
+	} else if len(maskedSecrets()) > 0 {
+		fmt.Fprintf(r.Out, "⚠️  Secrets are not masked in %s without a log writer\n", logFile)
+	}
+
+	// Detached processes can't use the terminal, so stdin is the null device
+	// unless a file is given, either with --stdin or in the command
+	if r.DetachedStdin == "" {
+		stdin, err := r.commandStdin(mainCmd, vars, nil)
+		if err != nil {
+			logFileHandle.Close()
+			return nil, fmt.Errorf("task %s: %w", taskName, err)
 		}
+		defer closeStdin(stdin)
+		cmd.Stdin = stdin
 	} else {
-		// On Unix-like systems, we'll handle process groups differently
-		// For now, use basic process creation and handle cleanup in stop command
-		cmd.SysProcAttr = &syscall.SysProcAttr{}
+		stdinFile, err := os.Open(r.DetachedStdin)
+		if err != nil {
+			logFileHandle.Close()
+			return nil, fmt.Errorf("failed to open stdin file: %w", err)
+		}
+		defer stdinFile.Close()
+		cmd.Stdin = stdinFile
 	}
 
+	// Set up process group for proper cleanup of child processes
+	setProcessGroup(cmd)
+
+	inherited := lock != nil && inheritLock(cmd, lock.file)
+
 	// Start the process
 	if err := cmd.Start(); err != nil {
 		logFileHandle.Close()
 		return nil, fmt.Errorf("failed to start detached process: %w", err)
 	}
+	if inherited {
+		lock.handOff(cmd.Process.Pid)
+		lock = nil
+	}
 
 	// Create detached process info
 	detachedProc := &DetachedProcess{
-		PID:       cmd.Process.Pid,
-		TaskName:  taskName,
-		Command:   cmdStr,
-		StartedAt: time.Now(),
-		LogFile:   logFile,
+		PID:          cmd.Process.Pid,
+		TaskName:     taskName,
+		Command:      cmdStr,
+		StartedAt:    time.Now(),
+		LogFile:      logFile,
+		LogWriterPID: logWriterPID,
+		Steps:        append(steps, cmdStr),
 	}
 
 	// Save process info to file for later reference
 	if err := r.saveDetachedProcess(detachedProc); err != nil {
-		fmt.Printf("⚠️  Warning: failed to save process info: %v\n", err)
+		fmt.Fprintf(r.Out, "⚠️  Warning: failed to save process info: %v\n", err)
 	}
 
-	fmt.Printf("✅ Task '%s' started in background (PID: %d)\n", taskName, cmd.Process.Pid)
-	fmt.Printf("📝 Logs: %s\n", logFile)
-	fmt.Printf("🛑 Stop with: t :stop %s (or PID %d)\n", taskName, cmd.Process.Pid)
+	fmt.Fprintf(r.Out, "✅ Task '%s' started in background (PID: %d)\n", taskName, cmd.Process.Pid)
+	fmt.Fprintf(r.Out, "📝 Logs: %s\n", logFile)
+	fmt.Fprintf(r.Out, "🛑 Stop with: t :stop %s (or PID %d)\n", taskName, cmd.Process.Pid)
 
 	// Start a goroutine to wait for the process and clean up
 	go func() {
@@ -461,9 +1603,15 @@ func (r *Runner) RunTaskDetached(taskName string) (*DetachedProcess, error) {
 	return detachedProc, nil
 }
 
+// detachedLogFile returns the log file for a detached task started now
+func (r *Runner) detachedLogFile(taskName string) string {
+	timestamp := time.Now().Format("20060102-150405")
+	return filepath.Join(r.LogsDir(), fmt.Sprintf("%s-%s.log", taskName, timestamp))
+}
+
 // saveDetachedProcess saves process info to a file
 func (r *Runner) saveDetachedProcess(proc *DetachedProcess) error {
-	processesDir := ".t-processes"
+	processesDir := r.ProcessesDir()
 	if err := os.MkdirAll(processesDir, 0755); err != nil {
 		return err
 	}
@@ -474,25 +1622,48 @@ func (r *Runner) saveDetachedProcess(proc *DetachedProcess) error {
 		return err
 	}
 
-	return os.WriteFile(filename, data, 0644)
+	unlock, err := lockRegistry(processesDir)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	return writeFileAtomic(filename, data, 0644)
 }
 
 // removeDetachedProcess removes process info file
 func (r *Runner) removeDetachedProcess(pid int) {
-	processesDir := ".t-processes"
+	processesDir := r.ProcessesDir()
 	filename := filepath.Join(processesDir, fmt.Sprintf("%d.json", pid))
+
+	unlock, err := lockRegistry(processesDir)
+	if err != nil {
+		return
+	}
+	defer unlock()
+
 	os.Remove(filename) // Ignore errors
 }
 
 // ListDetachedProcesses returns all currently tracked detached processes
 func (r *Runner) ListDetachedProcesses() ([]*DetachedProcess, error) {
-	processesDir := ".t-processes"
+	processesDir := r.ProcessesDir()
 
 	// Check if directory exists
 	if _, err := os.Stat(processesDir); os.IsNotExist(err) {
 		return []*DetachedProcess{}, nil
 	}
 
+	unlock, err := lockRegistry(processesDir)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	// Writers hold the lock while writing, so any temp file is left over
+	// from an interrupted write
+	removeStaleTempFiles(processesDir)
+
 	files, err := filepath.Glob(filepath.Join(processesDir, "*.json"))
 	if err != nil {
 		return nil, err
@@ -522,6 +1693,34 @@ func (r *Runner) ListDetachedProcesses() ([]*DetachedProcess, error) {
 	return processes, nil
 }
 
+// StaleDetachedProcesses returns registry entries whose process is no longer
+// running. Unlike ListDetachedProcesses it leaves the entries in place.
+func (r *Runner) StaleDetachedProcesses() ([]*DetachedProcess, error) {
+	files, err := filepath.Glob(filepath.Join(r.ProcessesDir(), "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var stale []*DetachedProcess
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+
+		var proc DetachedProcess
+		if err := json.Unmarshal(data, &proc); err != nil {
+			continue
+		}
+
+		if !r.isProcessRunning(proc.PID) {
+			stale = append(stale, &proc)
+		}
+	}
+
+	return stale, nil
+}
+
 // isProcessRunning checks if a process with the given PID is still running
 func (r *Runner) isProcessRunning(pid int) bool {
 	if runtime.GOOS == "windows" {
@@ -539,76 +1738,220 @@ func (r *Runner) isProcessRunning(pid int) bool {
 	}
 }
 
-// StopDetachedProcess stops a detached process by PID or task name
-func (r *Runner) StopDetachedProcess(identifier string) error {
+// FindDetachedProcess looks up a running detached process by PID or task name
+func (r *Runner) FindDetachedProcess(identifier string) (*DetachedProcess, error) {
 	processes, err := r.ListDetachedProcesses()
+	if err != nil {
+		return nil, err
+	}
+
+	pid, pidErr := strconv.Atoi(identifier)
+	for _, proc := range processes {
+		if (pidErr == nil && proc.PID == pid) || proc.TaskName == identifier {
+			return proc, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w with identifier: %s", ErrProcessNotFound, identifier)
+}
+
+// waitForExit polls until the process exits or the timeout elapses and
+// reports whether the process is gone
+func (r *Runner) waitForExit(pid int, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for r.isProcessRunning(pid) {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return true
+}
+
+// StopDetachedProcess stops detached processes by PID, task name or log file
+// path. A task name or log file stops every running process that matches.
+// Each process is asked to exit and killed if it is still running after grace.
+func (r *Runner) StopDetachedProcess(identifier string, grace time.Duration) error {
+	targets, err := r.detachedTargets(identifier)
 	if err != nil {
 		return err
 	}
 
-	var targetPID int
-	var targetProc *DetachedProcess
+	// Stop all targets at the same time so their grace periods overlap
+	errs := make([]error, len(targets))
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		wg.Add(1)
+		go func(i int, target *DetachedProcess) {
+			defer wg.Done()
 
-	// Try to parse as PID first
-	if pid, err := strconv.Atoi(identifier); err == nil {
-		targetPID = pid
-		// Find the process info
-		for _, proc := range processes {
-			if proc.PID == pid {
-				targetProc = proc
-				break
+			// Ask the process and its children to exit, escalating after the grace period
+			if err := r.terminateProcess(target.PID, grace); err != nil {
+				errs[i] = fmt.Errorf("failed to stop process %d: %w", target.PID, err)
+				return
+			}
+
+			// Clean up process info
+			r.removeDetachedProcess(target.PID)
+
+			if target.TaskName != "" {
+				fmt.Fprintf(r.Out, "🛑 Stopped detached task '%s' (PID: %d)\n", target.TaskName, target.PID)
+			} else {
+				fmt.Fprintf(r.Out, "🛑 Stopped process (PID: %d)\n", target.PID)
+			}
+		}(i, target)
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// SignalDetachedProcess sends a signal, given by name or number, to the
+// detached processes matching identifier like StopDetachedProcess does. The
+// processes stay in the registry.
+func (r *Runner) SignalDetachedProcess(identifier string, signal string) error {
+	targets, err := r.detachedTargets(identifier)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, target := range targets {
+		if err := r.signalProcess(target.PID, signal); err != nil {
+			if errors.Is(err, ErrUnknownSignal) {
+				return err
 			}
+			errs = append(errs, fmt.Errorf("failed to signal process %d: %w", target.PID, err))
+			continue
 		}
-	} else {
-		// Search by task name
-		for _, proc := range processes {
-			if proc.TaskName == identifier {
-				targetPID = proc.PID
-				targetProc = proc
-				break
+
+		if target.TaskName != "" {
+			fmt.Fprintf(r.Out, "📨 Sent %s to detached task '%s' (PID: %d)\n", signal, target.TaskName, target.PID)
+		} else {
+			fmt.Fprintf(r.Out, "📨 Sent %s to process (PID: %d)\n", signal, target.PID)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// PauseDetachedProcess pauses the detached processes matching identifier, like
+// StopDetachedProcess does, by sending SIGSTOP to their process group. They
+// keep their memory and open files but get no CPU until resumed.
+func (r *Runner) PauseDetachedProcess(identifier string) error {
+	return r.setDetachedState(identifier, "STOP", ProcessPaused)
+}
+
+// ResumeDetachedProcess lets paused detached processes matching identifier
+// run again by sending SIGCONT to their process group
+func (r *Runner) ResumeDetachedProcess(identifier string) error {
+	return r.setDetachedState(identifier, "CONT", "")
+}
+
+// setDetachedState sends signal to the detached processes matching
+// identifier and records their new state in the registry
+func (r *Runner) setDetachedState(identifier string, signal string, state string) error {
+	if runtime.GOOS == "windows" {
+		return errors.New("pausing and resuming detached tasks isn't supported on Windows")
+	}
+
+	targets, err := r.detachedTargets(identifier)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, target := range targets {
+		if err := r.signalProcess(target.PID, signal); err != nil {
+			errs = append(errs, fmt.Errorf("failed to signal process %d: %w", target.PID, err))
+			continue
+		}
+
+		name := fmt.Sprintf("process (PID: %d)", target.PID)
+		if target.TaskName != "" {
+			name = fmt.Sprintf("detached task '%s' (PID: %d)", target.TaskName, target.PID)
+			target.State = state
+			if err := r.saveDetachedProcess(target); err != nil {
+				errs = append(errs, fmt.Errorf("failed to update process %d: %w", target.PID, err))
 			}
 		}
+		if state == ProcessPaused {
+			fmt.Fprintf(r.Out, "⏸️  Paused %s\n", name)
+		} else {
+			fmt.Fprintf(r.Out, "▶️  Resumed %s\n", name)
+		}
 	}
 
-	if targetPID == 0 {
-		return fmt.Errorf("no detached process found with identifier: %s", identifier)
+	return errors.Join(errs...)
+}
+
+// ReopenDetachedLog makes the log writers of the detached processes matching
+// identifier reopen their log file, after it was rotated for example. It
+// sends them SIGHUP, which Windows doesn't have; there the writers only
+// reopen a log file once they notice it was moved.
+func (r *Runner) ReopenDetachedLog(identifier string) error {
+	targets, err := r.detachedTargets(identifier)
+	if err != nil {
+		return err
 	}
 
-	// Kill the process and its children
-	if runtime.GOOS == "windows" {
-		// On Windows, use taskkill with /T flag to kill the process tree
-		cmd := exec.Command("taskkill", "/F", "/T", "/PID", strconv.Itoa(targetPID))
-		if err := cmd.Run(); err != nil {
-			return fmt.Errorf("failed to kill process tree %d: %w", targetPID, err)
+	var errs []error
+	for _, target := range targets {
+		if target.LogWriterPID == 0 {
+			errs = append(errs, fmt.Errorf("process %d writes its log file directly, restart it to get a log writer", target.PID))
+			continue
 		}
-	} else {
-		// On Unix-like systems, try to kill the process group first, then the process
-		// First try to kill the process group (negative PID)
-		killGroupCmd := exec.Command("kill", fmt.Sprintf("-%d", targetPID))
-		killGroupErr := killGroupCmd.Run()
-
-		// Also kill the main process directly
-		killCmd := exec.Command("kill", strconv.Itoa(targetPID))
-		killErr := killCmd.Run()
-
-		// If both fail, try a more aggressive approach
-		if killGroupErr != nil && killErr != nil {
-			// Try SIGKILL
-			killForceCmd := exec.Command("kill", "-9", strconv.Itoa(targetPID))
-			if err := killForceCmd.Run(); err != nil {
-				return fmt.Errorf("failed to kill process %d: %w", targetPID, err)
+		if err := r.signalProcess(target.LogWriterPID, "HUP"); err != nil {
+			if errors.Is(err, ErrUnknownSignal) {
+				return err
 			}
+			errs = append(errs, fmt.Errorf("failed to signal log writer %d: %w", target.LogWriterPID, err))
+			continue
 		}
+		fmt.Fprintf(r.Out, "📨 Asked the log writer of '%s' (PID: %d) to reopen %s\n", target.TaskName, target.LogWriterPID, target.LogFile)
 	}
 
-	// Clean up process info
-	r.removeDetachedProcess(targetPID)
+	return errors.Join(errs...)
+}
 
-	if targetProc != nil {
-		fmt.Printf("🛑 Stopped detached task '%s' (PID: %d)\n", targetProc.TaskName, targetPID)
+// detachedTargets resolves a PID, task name or log file path to the
+// detached processes it refers to. Only processes in the registry are
+// returned, as they lead their own process group and are safe to signal
+// as a group.
+func (r *Runner) detachedTargets(identifier string) ([]*DetachedProcess, error) {
+	processes, err := r.ListDetachedProcesses()
+	if err != nil {
+		return nil, err
+	}
+
+	var targets []*DetachedProcess
+	if pid, err := strconv.Atoi(identifier); err == nil {
+		for _, proc := range processes {
+			if proc.PID == pid {
+				targets = append(targets, proc)
+				break
+			}
+		}
 	} else {
-		fmt.Printf("🛑 Stopped process (PID: %d)\n", targetPID)
+		for _, proc := range processes {
+			if proc.TaskName == identifier || sameFile(proc.LogFile, identifier) {
+				targets = append(targets, proc)
+			}
+		}
 	}
 
-	return nil
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("%w with identifier: %s", ErrProcessNotFound, identifier)
+	}
+	return targets, nil
+}
+
+// sameFile reports whether two paths refer to the same file location
+func sameFile(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && absA == absB
 }
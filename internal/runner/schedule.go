@@ -0,0 +1,52 @@
+package runner
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// ScheduleTask starts argv, a t process that waits until at and then runs
+// taskName, in the background the way RunTaskDetached does: in its own
+// process group, logging to a file and registered so that :ps shows it and
+// :stop cancels it
+func (r *Runner) ScheduleTask(taskName string, at time.Time, argv []string) (*DetachedProcess, error) {
+	if _, exists := r.Config.Tasks[taskName]; !exists {
+		return nil, r.taskNotFound(taskName)
+	}
+
+	if err := os.MkdirAll(r.LogsDir(), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create logs directory: %w", err)
+	}
+	logFile := r.detachedLogFile(taskName)
+	logFileHandle, err := os.Create(logFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create log file: %w", err)
+	}
+	defer logFileHandle.Close()
+
+	cmd := exec.Command(argv[0], argv[1:]...)
+	cmd.Stdout = logFileHandle
+	cmd.Stderr = logFileHandle
+	r.setCommandEnv(cmd)
+	setProcessGroup(cmd)
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start scheduled run: %w", err)
+	}
+
+	proc := &DetachedProcess{
+		PID:         cmd.Process.Pid,
+		TaskName:    taskName,
+		Command:     fmt.Sprintf("t %s", taskName),
+		StartedAt:   time.Now(),
+		LogFile:     logFile,
+		ScheduledAt: &at,
+	}
+	if err := r.saveDetachedProcess(proc); err != nil {
+		fmt.Fprintf(r.Out, "⚠️  Warning: failed to save process info: %v\n", err)
+	}
+	go cmd.Wait()
+
+	return proc, nil
+}
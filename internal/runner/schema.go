@@ -0,0 +1,122 @@
+package runner
+
+import (
+	"reflect"
+	"strings"
+)
+
+// schemaScalar accepts any YAML scalar, as a string field decodes numbers
+// and booleans too
+var schemaScalar = map[string]any{"type": []string{"string", "number", "boolean"}}
+
+// ConfigSchema returns a JSON Schema (draft-07) of the config format, for
+// editors to complete and check tasks.yaml with. It is generated from Config
+// and the types it holds, so it follows them; types with their own
+// UnmarshalYAML are described in customSchema.
+func ConfigSchema() map[string]any {
+	definitions := make(map[string]any)
+	schema := structSchema(reflect.TypeOf(Config{}), definitions)
+	schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+	schema["title"] = "t tasks.yaml"
+	schema["definitions"] = definitions
+	return schema
+}
+
+// typeSchema returns the schema of a Go type. Named structs and types with a
+// custom form go into definitions once and are referenced from then on.
+func typeSchema(typ reflect.Type, definitions map[string]any) map[string]any {
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	custom := customSchema(typ, definitions)
+	if custom != nil || (typ.Kind() == reflect.Struct && typ.Name() != "") {
+		ref := map[string]any{"$ref": "#/definitions/" + typ.Name()}
+		if _, ok := definitions[typ.Name()]; ok {
+			return ref
+		}
+		// Reserved first, so a type that holds itself doesn't recurse
+		definitions[typ.Name()] = nil
+		if custom == nil {
+			custom = structSchema(typ, definitions)
+		}
+		definitions[typ.Name()] = custom
+		return ref
+	}
+
+	switch typ.Kind() {
+	case reflect.String:
+		return schemaScalar
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int64:
+		return map[string]any{"type": "integer"}
+	case reflect.Slice:
+		return map[string]any{"type": "array", "items": typeSchema(typ.Elem(), definitions)}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": typeSchema(typ.Elem(), definitions)}
+	case reflect.Struct:
+		return structSchema(typ, definitions)
+	}
+	return map[string]any{}
+}
+
+// structSchema returns the schema of a struct as a mapping of its yaml
+// fields. Other keys are rejected, as the config is decoded strictly.
+func structSchema(typ reflect.Type, definitions map[string]any) map[string]any {
+	properties := make(map[string]any)
+	for i := 0; i < typ.NumField(); i++ {
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("yaml"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		properties[name] = typeSchema(typ.Field(i).Type, definitions)
+	}
+	return map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
+
+// customSchema describes the types whose UnmarshalYAML accepts more than
+// their fields, or returns nil for other types
+func customSchema(typ reflect.Type, definitions map[string]any) map[string]any {
+	switch typ {
+	case reflect.TypeOf(Command{}):
+		return map[string]any{"oneOf": []any{
+			map[string]any{"type": "string"},
+			structSchema(typ, definitions),
+		}}
+	case reflect.TypeOf(Dep{}):
+		object := structSchema(typ, definitions)
+		object["required"] = []string{"task"}
+		return map[string]any{"oneOf": []any{
+			map[string]any{"type": "string"},
+			object,
+		}}
+	case reflect.TypeOf(Deps{}):
+		dep := typeSchema(reflect.TypeOf(Dep{}), definitions)
+		return map[string]any{
+			"type": "array",
+			"items": map[string]any{"anyOf": []any{
+				dep,
+				map[string]any{"type": "array", "items": dep},
+			}},
+		}
+	case reflect.TypeOf(Vars{}):
+		secret := map[string]any{
+			"type":                 "object",
+			"properties":           map[string]any{"secret": map[string]any{"type": "string"}},
+			"required":             []string{"secret"},
+			"additionalProperties": false,
+		}
+		return map[string]any{
+			"type":                 "object",
+			"additionalProperties": map[string]any{"anyOf": []any{schemaScalar, secret}},
+		}
+	case reflect.TypeOf(Confirm{}), reflect.TypeOf(Lock{}):
+		return map[string]any{"type": []string{"boolean", "string"}}
+	}
+	return nil
+}
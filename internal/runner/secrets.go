@@ -0,0 +1,302 @@
+package runner
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Vars maps variable names to values. Besides a plain string, a value can be
+// a secret reference such as {secret: "env://DB_PASS"} or
+// {secret: "file:///run/secrets/db"}, resolved when the config is loaded so
+// the secret itself never has to be written in tasks.yaml.
+type Vars map[string]string
+
+// SecretResolver returns the secret a reference points to, given the part of
+// the reference after "scheme://"
+type SecretResolver func(path string) (string, error)
+
+var (
+	// secretResolvers maps reference schemes to their resolver
+	secretResolvers = map[string]SecretResolver{
+		"env":  resolveEnvSecret,
+		"file": resolveFileSecret,
+	}
+	// secrets maps every resolved secret to the reference it came from, so
+	// it can be masked wherever it would be printed
+	secrets      = make(map[string]string)
+	secretsMutex sync.RWMutex
+)
+
+// minMaskedSecret is the length below which secrets aren't masked, as
+// masking a value that short would garble unrelated output
+const minMaskedSecret = 4
+
+// RegisterSecretResolver adds a reference scheme, or replaces the resolver of
+// an existing one. Programs embedding the runner call it before loading a
+// config that uses the scheme.
+func RegisterSecretResolver(scheme string, resolve SecretResolver) {
+	secretsMutex.Lock()
+	defer secretsMutex.Unlock()
+
+	secretResolvers[scheme] = resolve
+}
+
+// resolveEnvSecret reads a secret from an environment variable
+func resolveEnvSecret(name string) (string, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+	return value, nil
+}
+
+// resolveFileSecret reads a secret from a file, such as one mounted by
+// Docker or Kubernetes, without its trailing newline
+func resolveFileSecret(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// resolveSecret resolves a secret reference and remembers the value as a
+// secret
+func resolveSecret(ref string) (string, error) {
+	scheme, path, ok := strings.Cut(ref, "://")
+	secretsMutex.RLock()
+	resolve, known := secretResolvers[scheme]
+	secretsMutex.RUnlock()
+	if !ok || !known {
+		return "", fmt.Errorf("secret %q: expected a reference like %s", ref, secretSchemes())
+	}
+
+	value, err := resolve(path)
+	if err != nil {
+		return "", fmt.Errorf("secret %q: %w", ref, err)
+	}
+	if value != "" {
+		secretsMutex.Lock()
+		secrets[value] = ref
+		secretsMutex.Unlock()
+	}
+	return value, nil
+}
+
+// secretSchemes lists the known reference schemes, like "env://..., file://..."
+func secretSchemes() string {
+	secretsMutex.RLock()
+	defer secretsMutex.RUnlock()
+
+	schemes := make([]string, 0, len(secretResolvers))
+	for scheme := range secretResolvers {
+		schemes = append(schemes, scheme+"://...")
+	}
+	sort.Strings(schemes)
+	return strings.Join(schemes, ", ")
+}
+
+// MaskSecrets replaces every resolved secret in s with ***
+func MaskSecrets(s string) string {
+	for _, value := range maskedSecrets() {
+		s = strings.ReplaceAll(s, value, "***")
+	}
+	return s
+}
+
+// maskedSecrets returns the secrets to mask, longest first so a secret
+// containing another is masked whole
+func maskedSecrets() []string {
+	secretsMutex.RLock()
+	defer secretsMutex.RUnlock()
+
+	values := make([]string, 0, len(secrets))
+	for value := range secrets {
+		if len(value) >= minMaskedSecret {
+			values = append(values, value)
+		}
+	}
+	sort.Slice(values, func(i, j int) bool { return len(values[i]) > len(values[j]) })
+	return values
+}
+
+// maskingWriter masks secrets in every write on its own, for messages that
+// are written whole
+type maskingWriter struct {
+	out io.Writer
+}
+
+// MaskingWriter returns a writer that masks resolved secrets in what is
+// written to out. Each write is masked on its own; use NewMaskingStream for
+// output that may split a secret across writes.
+func MaskingWriter(out io.Writer) io.Writer {
+	return maskingWriter{out: out}
+}
+
+func (w maskingWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(w.out, MaskSecrets(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// maskingStream masks secrets in a stream of output such as a command's. The
+// end of a write that could be the start of a secret is held back until the
+// next write shows whether it is.
+type maskingStream struct {
+	out     io.Writer
+	pending string
+	mutex   sync.Mutex
+}
+
+// NewMaskingStream returns a writer that masks resolved secrets in the
+// output written to out, even when a secret is split across writes. Flush
+// writes out what is held back once the output ends.
+func NewMaskingStream(out io.Writer) LineWriter {
+	return &maskingStream{out: out}
+}
+
+func (w *maskingStream) Write(p []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	values := maskedSecrets()
+	text := w.pending + string(p)
+	for _, value := range values {
+		text = strings.ReplaceAll(text, value, "***")
+	}
+	held := secretStart(text, values)
+	w.pending = text[len(text)-held:]
+	if _, err := io.WriteString(w.out, text[:len(text)-held]); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Flush writes out the output held back as the possible start of a secret
+func (w *maskingStream) Flush() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.pending == "" {
+		return nil
+	}
+	_, err := io.WriteString(w.out, w.pending)
+	w.pending = ""
+	return err
+}
+
+// secretStart returns the length of the longest end of text that is the
+// start of one of values
+func secretStart(text string, values []string) int {
+	longest := 0
+	for _, value := range values {
+		for n := min(len(value)-1, len(text)); n > longest; n-- {
+			if strings.HasSuffix(text, value[:n]) {
+				longest = n
+				break
+			}
+		}
+	}
+	return longest
+}
+
+// writeLogSecrets hands the secrets to mask to the log writer of a detached
+// task as the first line of its input, a JSON list, so that they don't show
+// up in its environment or arguments
+func writeLogSecrets(w io.Writer) error {
+	data, err := json.Marshal(maskedSecrets())
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// ReadLogSecrets reads the secrets a log writer is handed on the first line
+// of its input, see writeLogSecrets, and registers them so they are masked.
+// The rest of the input is left to be read from input.
+func ReadLogSecrets(input *bufio.Reader) error {
+	line, err := input.ReadBytes('\n')
+	if err != nil {
+		return fmt.Errorf("reading secrets: %w", err)
+	}
+
+	var values []string
+	if err := json.Unmarshal(line, &values); err != nil {
+		return fmt.Errorf("reading secrets: %w", err)
+	}
+	secretsMutex.Lock()
+	defer secretsMutex.Unlock()
+	for _, value := range values {
+		secrets[value] = "log writer input"
+	}
+	return nil
+}
+
+// UnmarshalYAML resolves secret references while decoding the vars
+func (v *Vars) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind != yaml.MappingNode {
+		var plain map[string]string
+		if err := value.Decode(&plain); err != nil {
+			return err
+		}
+		*v = plain
+		return nil
+	}
+
+	vars := make(Vars, len(value.Content)/2)
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		name, node := value.Content[i].Value, value.Content[i+1]
+		if node.Kind != yaml.MappingNode {
+			var plain string
+			if err := node.Decode(&plain); err != nil {
+				return err
+			}
+			vars[name] = plain
+			continue
+		}
+
+		if len(node.Content) != 2 || node.Content[0].Value != "secret" {
+			return fmt.Errorf("line %d: var %s: expected a string or {secret: <reference>}", node.Line, name)
+		}
+		resolved, err := resolveSecret(node.Content[1].Value)
+		if err != nil {
+			return fmt.Errorf("line %d: var %s: %w", node.Line, name, err)
+		}
+		vars[name] = resolved
+	}
+	*v = vars
+	return nil
+}
+
+// MarshalYAML writes secrets back as their reference and masks secrets
+// within other values, so printing a config never reveals them
+func (v Vars) MarshalYAML() (any, error) {
+	out := make(map[string]any, len(v))
+	for name, value := range v {
+		if ref, ok := secretRef(value); ok {
+			out[name] = map[string]string{"secret": ref}
+		} else {
+			out[name] = MaskSecrets(value)
+		}
+	}
+	return out, nil
+}
+
+// secretRef returns the reference a secret was resolved from
+func secretRef(value string) (string, bool) {
+	secretsMutex.RLock()
+	defer secretsMutex.RUnlock()
+
+	ref, ok := secrets[value]
+	return ref, ok
+}
@@ -0,0 +1,75 @@
+package runner
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"testing"
+)
+
+// testSecret resolves value as a secret for the length of a test
+func testSecret(t *testing.T, value string) {
+	t.Helper()
+	t.Setenv("T_TEST_SECRET", value)
+	if _, err := resolveSecret("env://T_TEST_SECRET"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		secretsMutex.Lock()
+		delete(secrets, value)
+		secretsMutex.Unlock()
+	})
+}
+
+func TestMaskingStreamMasksSecretSplitAcrossWrites(t *testing.T) {
+	testSecret(t, "hunter22")
+
+	var out bytes.Buffer
+	stream := NewMaskingStream(&out)
+	for _, chunk := range []string{"token=hun", "ter22\nhu", "nt done\nlast hunt"} {
+		stream.Write([]byte(chunk))
+	}
+	if got, want := out.String(), "token=***\nhunt done\nlast "; got != want {
+		t.Errorf("before Flush got %q, want %q", got, want)
+	}
+	stream.Flush()
+	if got, want := out.String(), "token=***\nhunt done\nlast hunt"; got != want {
+		t.Errorf("after Flush got %q, want %q", got, want)
+	}
+}
+
+func TestShortSecretsAreNotMasked(t *testing.T) {
+	testSecret(t, "ab")
+	testSecret(t, "abcd")
+
+	var out bytes.Buffer
+	MaskingWriter(&out).Write([]byte("ab abc abcd\n"))
+	if got, want := out.String(), "ab abc ***\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestLogSecretsComeFirstOnTheInput(t *testing.T) {
+	testSecret(t, "hunter22")
+
+	var pipe bytes.Buffer
+	if err := writeLogSecrets(&pipe); err != nil {
+		t.Fatal(err)
+	}
+	pipe.WriteString("token=hunter22\n")
+
+	// The writer starts without the secret, as a separate process would
+	secretsMutex.Lock()
+	delete(secrets, "hunter22")
+	secretsMutex.Unlock()
+
+	input := bufio.NewReader(&pipe)
+	if err := ReadLogSecrets(input); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	io.Copy(MaskingWriter(&out), input)
+	if got, want := out.String(), "token=***\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,69 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+// The arguments cmd strings are run with when neither the config nor
+// Runner.ShellArgs sets them: sh -c, or powershell -Command on Windows
+var (
+	defaultShellArgs      = []string{"-c"}
+	defaultPowerShellArgs = []string{"-Command"}
+)
+
+// shellCommandFlag matches the flags sh takes the command string after: -c,
+// or a group of flags ending with it such as -ec
+var shellCommandFlag = regexp.MustCompile(`^-[a-zA-Z]*c$`)
+
+// CheckShellArgs reports arguments for the shell of this platform, as set
+// by Runner.ShellArgs, that don't end with the flag taking the command
+func CheckShellArgs(args []string) error {
+	if runtime.GOOS == "windows" {
+		return checkShellArgs("powershell", args)
+	}
+	return checkShellArgs("sh", args)
+}
+
+// checkShellArgs reports shell arguments that don't end with the flag taking
+// the command string, which is passed right after them. shell is "sh" or
+// "powershell".
+func checkShellArgs(shell string, args []string) error {
+	if len(args) == 0 {
+		return nil
+	}
+	last := args[len(args)-1]
+	if shell == "powershell" {
+		if !strings.EqualFold(last, "-Command") && !strings.EqualFold(last, "-c") {
+			return fmt.Errorf("powershell arguments must end with -Command, the flag taking the command: %v", args)
+		}
+		return nil
+	}
+	if !shellCommandFlag.MatchString(last) {
+		return fmt.Errorf("sh arguments must end with -c, the flag taking the command: %v", args)
+	}
+	return nil
+}
+
+// shellCommand returns the command running a cmd string through sh, or
+// PowerShell on Windows, with the shell arguments in effect
+func (r *Runner) shellCommand(ctx context.Context, script string) *exec.Cmd {
+	shell, args := "sh", defaultShellArgs
+	if runtime.GOOS == "windows" {
+		shell, args = "powershell", defaultPowerShellArgs
+		if len(r.Config.PowerShellArgs) > 0 {
+			args = r.Config.PowerShellArgs
+		}
+	} else if len(r.Config.ShellArgs) > 0 {
+		args = r.Config.ShellArgs
+	}
+	if len(r.ShellArgs) > 0 {
+		args = r.ShellArgs
+	}
+
+	return exec.CommandContext(ctx, shell, append(args[:len(args):len(args)], script)...)
+}
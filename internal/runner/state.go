@@ -0,0 +1,63 @@
+package runner
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// LogsDir returns the directory detached task logs are written to:
+// StateDir/logs, or .t-logs when no state dir is set
+func (r *Runner) LogsDir() string {
+	if r.StateDir == "" {
+		return ".t-logs"
+	}
+	return filepath.Join(r.StateDir, "logs")
+}
+
+// ProcessesDir returns the directory of the detached process registry:
+// StateDir/processes, or .t-processes when no state dir is set
+func (r *Runner) ProcessesDir() string {
+	if r.StateDir == "" {
+		return ".t-processes"
+	}
+	return filepath.Join(r.StateDir, "processes")
+}
+
+// historyFile returns the file runs are recorded in
+func (r *Runner) historyFile() string {
+	if r.StateDir == "" {
+		return filepath.Join(".t", "history.jsonl")
+	}
+	return filepath.Join(r.StateDir, "history.jsonl")
+}
+
+// ResolveStateDir turns a state_dir setting into a directory. "xdg" selects a
+// per-project directory under $XDG_STATE_HOME/t (or ~/.local/state/t) named
+// after the current directory, so state stays out of the repository. Any
+// other value is used as a path.
+func ResolveStateDir(value string) (string, error) {
+	if value != "xdg" {
+		return value, nil
+	}
+
+	stateHome := os.Getenv("XDG_STATE_HOME")
+	if stateHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("cannot find the XDG state directory: %w", err)
+		}
+		stateHome = filepath.Join(home, ".local", "state")
+	}
+
+	project, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	// The hash keeps projects with the same directory name apart
+	sum := sha256.Sum256([]byte(project))
+	name := fmt.Sprintf("%s-%x", filepath.Base(project), sum[:4])
+
+	return filepath.Join(stateHome, "t", name), nil
+}
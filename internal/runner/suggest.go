@@ -0,0 +1,89 @@
+package runner
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maxSuggestions caps how many close task names SuggestTasks returns
+const maxSuggestions = 3
+
+// SuggestTasks returns up to three task names close to name by edit
+// distance, closest first. Internal tasks are only suggested for names that
+// look internal themselves.
+func SuggestTasks(config *Config, name string) []string {
+	// Allow roughly one typo per three characters, and at least a swap
+	maxDistance := len(name) / 3
+	if maxDistance < 2 {
+		maxDistance = 2
+	}
+
+	type candidate struct {
+		name     string
+		distance int
+	}
+	var candidates []candidate
+	for taskName, task := range config.Tasks {
+		if IsInternalTask(taskName, task) && !strings.HasPrefix(name, "_") {
+			continue
+		}
+		distance := levenshtein(strings.ToLower(name), strings.ToLower(taskName))
+		if distance <= maxDistance && distance < len(name) {
+			candidates = append(candidates, candidate{taskName, distance})
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].distance != candidates[j].distance {
+			return candidates[i].distance < candidates[j].distance
+		}
+		return candidates[i].name < candidates[j].name
+	})
+
+	var suggestions []string
+	for i := 0; i < len(candidates) && i < maxSuggestions; i++ {
+		suggestions = append(suggestions, candidates[i].name)
+	}
+	return suggestions
+}
+
+// taskNotFound builds the ErrTaskNotFound error for name, suggesting close
+// task names when there are any
+func (r *Runner) taskNotFound(name string) error {
+	suggestions := SuggestTasks(r.Config, name)
+	if len(suggestions) == 0 {
+		return fmt.Errorf("%w: %s", ErrTaskNotFound, name)
+	}
+
+	quoted := make([]string, len(suggestions))
+	for i, suggestion := range suggestions {
+		quoted[i] = fmt.Sprintf("%q", suggestion)
+	}
+	return fmt.Errorf("%w: %s. Did you mean %s?", ErrTaskNotFound, name, strings.Join(quoted, " or "))
+}
+
+// levenshtein returns the number of single character insertions, deletions
+// and substitutions needed to turn a into b
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
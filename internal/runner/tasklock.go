@@ -0,0 +1,226 @@
+package runner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// lockPollInterval is how often a task waiting for its lock tries again
+const lockPollInterval = 200 * time.Millisecond
+
+// Lock holds a task's lock, which keeps separate runs of t from running it at
+// the same time. It is written either as `lock: true` for a lock of the task
+// alone or as `lock: "name"` for a lock shared by every task naming it.
+type Lock struct {
+	Enabled bool
+	Name    string
+}
+
+// UnmarshalYAML accepts both the boolean and the name form
+func (l *Lock) UnmarshalYAML(value *yaml.Node) error {
+	if value.Tag == "!!bool" {
+		return value.Decode(&l.Enabled)
+	}
+
+	l.Enabled = true
+	return value.Decode(&l.Name)
+}
+
+// MarshalYAML writes the lock as true or as its name
+func (l Lock) MarshalYAML() (any, error) {
+	if l.Name != "" {
+		return l.Name, nil
+	}
+	return l.Enabled, nil
+}
+
+// LockName returns the name of the lock a task takes: its own name unless
+// the lock is named
+func (l Lock) LockName(taskName string) string {
+	if l.Name != "" {
+		return l.Name
+	}
+	return taskName
+}
+
+// unsafeLockChars are replaced in lock names to make file names of them
+var unsafeLockChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// processLocks holds a mutex per lock file, as a file lock doesn't keep
+// tasks of the same process apart. Such tasks, like parallel deps, wait for
+// each other's lock only when WaitForLock is set, like separate runs do.
+var processLocks sync.Map
+
+// taskLock is a lock held by a running task
+type taskLock struct {
+	runner *Runner
+	path   string
+	file   *os.File
+	mutex  *sync.Mutex
+}
+
+// LocksDir returns the directory of task lock files: StateDir/locks, or
+// .t/locks when no state dir is set
+func (r *Runner) LocksDir() string {
+	if r.StateDir == "" {
+		return filepath.Join(".t", "locks")
+	}
+	return filepath.Join(r.StateDir, "locks")
+}
+
+// acquireTaskLock takes the lock of a task. When another run or another task
+// of this run holds it, it waits for it if WaitForLock is set and fails with
+// ErrTaskLocked otherwise.
+// A lock already held by a task in path, such as a task running another one
+// with the same lock, is shared with it and returned as nil.
+func (r *Runner) acquireTaskLock(taskName string, task Task, path []string) (*taskLock, error) {
+	name := task.Lock.LockName(taskName)
+	dir := r.LocksDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create locks directory: %w", err)
+	}
+	lockPath, err := filepath.Abs(filepath.Join(dir, unsafeLockChars.ReplaceAllString(name, "_")+".lock"))
+	if err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	holder := r.lockHolders[lockPath]
+	r.mutex.RUnlock()
+	if holder != "" && slices.Contains(path[:len(path)-1], holder) {
+		return nil, nil
+	}
+
+	value, _ := processLocks.LoadOrStore(lockPath, &sync.Mutex{})
+	mutex := value.(*sync.Mutex)
+
+	var file *os.File
+	waiting := false
+	for {
+		var heldBy string
+		file, heldBy, err = r.tryTaskLock(mutex, lockPath)
+		if err != nil {
+			return nil, err
+		}
+		if file != nil {
+			break
+		}
+
+		if !r.WaitForLock {
+			return nil, fmt.Errorf("%w: %s%s (wait for it with --wait)", ErrTaskLocked, name, heldBy)
+		}
+		if !waiting {
+			fmt.Fprintf(r.taskOut(taskName), "⏳ Waiting for lock %s%s\n", name, heldBy)
+			waiting = true
+		}
+
+		select {
+		case <-r.context().Done():
+			return nil, fmt.Errorf("waiting for lock %s: %w", name, r.context().Err())
+		case <-time.After(lockPollInterval):
+		}
+	}
+
+	r.mutex.Lock()
+	if r.lockHolders == nil {
+		r.lockHolders = make(map[string]string)
+	}
+	r.lockHolders[lockPath] = taskName
+	r.mutex.Unlock()
+
+	lock := &taskLock{runner: r, path: lockPath, file: file, mutex: mutex}
+	lock.writePID(os.Getpid())
+	return lock, nil
+}
+
+// tryTaskLock takes a lock if it is free: the mutex of this process first,
+// then the lock file. It returns the locked file, or nil and a description
+// of the holder when the lock is taken.
+func (r *Runner) tryTaskLock(mutex *sync.Mutex, lockPath string) (*os.File, string, error) {
+	if !mutex.TryLock() {
+		r.mutex.RLock()
+		holder := r.lockHolders[lockPath]
+		r.mutex.RUnlock()
+		if holder == "" {
+			return nil, " (held by this process)", nil
+		}
+		return nil, fmt.Sprintf(" (held by task %s)", holder), nil
+	}
+
+	file, err := os.OpenFile(lockPath, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		mutex.Unlock()
+		return nil, "", fmt.Errorf("failed to open lock file: %w", err)
+	}
+	locked, err := tryLockFile(file)
+	if err != nil || !locked {
+		file.Close()
+		mutex.Unlock()
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to lock %s: %w", lockPath, err)
+		}
+		return nil, lockHolder(lockPath), nil
+	}
+	return file, "", nil
+}
+
+// lockHolder describes the process holding a lock, as " (held by PID n)",
+// or returns "" when that isn't known
+func lockHolder(lockPath string) string {
+	// Windows doesn't let other processes read a locked file
+	data, err := os.ReadFile(lockPath)
+	if err != nil {
+		return ""
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf(" (held by PID %d)", pid)
+}
+
+// release unlocks the lock. It does nothing for a nil lock, which a task
+// shares with one that called it.
+func (l *taskLock) release() {
+	if l == nil {
+		return
+	}
+	unlockFile(l.file)
+	l.close()
+}
+
+// writePID records the process holding the lock in its file, for other runs
+// waiting for it
+func (l *taskLock) writePID(pid int) {
+	l.file.Truncate(0)
+	l.file.WriteAt([]byte(strconv.Itoa(pid)+"\n"), 0)
+}
+
+// handOff lets go of the lock without unlocking it, for a detached process
+// that inherited the lock file and holds the lock from then on
+func (l *taskLock) handOff(pid int) {
+	l.writePID(pid)
+	l.close()
+}
+
+// close closes the lock file and lets other tasks of this process take the
+// lock
+func (l *taskLock) close() {
+	if l == nil {
+		return
+	}
+	l.file.Close()
+	l.runner.mutex.Lock()
+	delete(l.runner.lockHolders, l.path)
+	l.runner.mutex.Unlock()
+	l.mutex.Unlock()
+}
@@ -0,0 +1,112 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// lockedDeps runs two deps sharing a lock at the same time
+const lockedDeps = `
+tasks:
+  all:
+    deps: [migrate, seed]
+  migrate:
+    lock: db
+    cmds: ["sleep 0.3"]
+  seed:
+    lock: db
+    cmds: ["sleep 0.3"]
+`
+
+func TestSameRunLockFailsWithoutWait(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("cmd strings run through PowerShell on Windows")
+	}
+	r := testRunner(t, lockedDeps)
+	r.StateDir = t.TempDir()
+
+	if err := r.RunTask("all"); !errors.Is(err, ErrTaskLocked) {
+		t.Errorf("got %v, want %v", err, ErrTaskLocked)
+	}
+}
+
+func TestSameRunLockWaitsWithWait(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("cmd strings run through PowerShell on Windows")
+	}
+	r := testRunner(t, lockedDeps)
+	r.StateDir = t.TempDir()
+	r.WaitForLock = true
+
+	if err := r.RunTask("all"); err != nil {
+		t.Fatal(err)
+	}
+	if !r.Ran["migrate"] || !r.Ran["seed"] {
+		t.Errorf("ran %v, want both deps", r.Ran)
+	}
+}
+
+// TestOppositeOrderLocksEnd checks that two tasks taking two named locks in
+// opposite order end rather than wait for each other forever
+func TestOppositeOrderLocksEnd(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("cmd strings run through PowerShell on Windows")
+	}
+	config := `
+tasks:
+  all:
+    deps: [first, second]
+  first:
+    lock: one
+    cmds: ["sleep 0.2", {task: needs-two}]
+  second:
+    lock: two
+    cmds: ["sleep 0.2", {task: needs-one}]
+  needs-one:
+    lock: one
+    cmds: ["true"]
+  needs-two:
+    lock: two
+    cmds: ["true"]
+`
+	for _, wait := range []bool{false, true} {
+		r := testRunner(t, config)
+		r.StateDir = t.TempDir()
+		r.WaitForLock = wait
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		r.Context = ctx
+
+		done := make(chan error, 1)
+		go func() { done <- r.RunTask("all") }()
+		select {
+		case err := <-done:
+			if err == nil {
+				t.Errorf("wait=%v: both tasks got both locks", wait)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("wait=%v: tasks deadlocked on their locks", wait)
+		}
+	}
+}
+
+func TestIterationRunnerKeepsSettings(t *testing.T) {
+	r := testRunner(t, "tasks: {}\n")
+	r.StateDir = t.TempDir()
+	r.WaitForLock = true
+	r.Timeout = time.Minute
+	r.Ran["build"] = true
+	r.Timings = append(r.Timings, TaskTiming{Task: "build"})
+
+	iteration := r.iterationRunner()
+	if iteration.StateDir != r.StateDir || !iteration.WaitForLock || iteration.Timeout != time.Minute || iteration.Dir != r.Dir {
+		t.Errorf("settings weren't copied: state dir %q, wait %v, timeout %v, dir %q",
+			iteration.StateDir, iteration.WaitForLock, iteration.Timeout, iteration.Dir)
+	}
+	if len(iteration.Ran) > 0 || len(iteration.Timings) > 0 {
+		t.Errorf("run state was copied: ran %v, timings %v", iteration.Ran, iteration.Timings)
+	}
+}
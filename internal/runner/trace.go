@@ -0,0 +1,115 @@
+package runner
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// Trace records task and command spans in the Chrome trace event format, for
+// chrome://tracing, Perfetto or speedscope. Each task run gets its own row
+// with its commands nested under it. A nil *Trace records nothing.
+type Trace struct {
+	mutex  sync.Mutex
+	start  time.Time
+	events []traceEvent
+	lanes  map[string]int
+	last   int
+}
+
+// traceEvent is a single event of the trace event format. Times are in
+// microseconds since the trace started.
+type traceEvent struct {
+	Name string            `json:"name"`
+	Cat  string            `json:"cat,omitempty"`
+	Ph   string            `json:"ph"`
+	Ts   int64             `json:"ts"`
+	Dur  int64             `json:"dur,omitempty"`
+	Pid  int               `json:"pid"`
+	Tid  int               `json:"tid"`
+	Args map[string]string `json:"args,omitempty"`
+}
+
+// NewTrace starts an empty trace
+func NewTrace() *Trace {
+	return &Trace{start: time.Now(), lanes: make(map[string]int)}
+}
+
+// beginTask gives a task run its own row in the trace and returns it
+func (t *Trace) beginTask(taskName string) int {
+	if t == nil {
+		return 0
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.last++
+	lane := t.last
+	t.lanes[taskName] = lane
+	t.events = append(t.events, traceEvent{
+		Name: "thread_name",
+		Ph:   "M",
+		Pid:  1,
+		Tid:  lane,
+		Args: map[string]string{"name": taskName},
+	})
+	return lane
+}
+
+// lane returns the row of the latest run of a task
+func (t *Trace) lane(taskName string) int {
+	if t == nil {
+		return 0
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.lanes[taskName]
+}
+
+// addSpan records a span that started at start and ends now
+func (t *Trace) addSpan(name, category string, lane int, start time.Time, args map[string]string) {
+	if t == nil {
+		return
+	}
+
+	end := time.Now()
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.events = append(t.events, traceEvent{
+		Name: name,
+		Cat:  category,
+		Ph:   "X",
+		Ts:   start.Sub(t.start).Microseconds(),
+		Dur:  end.Sub(start).Microseconds(),
+		Pid:  1,
+		Tid:  lane,
+		Args: args,
+	})
+}
+
+// WriteFile writes the trace as JSON. The file is replaced atomically so a
+// viewer never sees a partial trace.
+func (t *Trace) WriteFile(filename string) error {
+	t.mutex.Lock()
+	data, err := json.MarshalIndent(struct {
+		TraceEvents     []traceEvent `json:"traceEvents"`
+		DisplayTimeUnit string       `json:"displayTimeUnit"`
+	}{t.events, "ms"}, "", "  ")
+	t.mutex.Unlock()
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(filename, data, 0644)
+}
+
+// traceStatus describes the outcome of a span for its args
+func traceStatus(err error) string {
+	if err != nil {
+		return "failed"
+	}
+	return "ok"
+}
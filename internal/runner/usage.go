@@ -0,0 +1,15 @@
+package runner
+
+// Usage is the resource usage of a detached process and its children.
+// CPUPercent is averaged over the process lifetime like ps reports it, and is
+// -1 where the platform doesn't provide it.
+type Usage struct {
+	CPUPercent float64 `json:"cpu_percent"`
+	RSSBytes   int64   `json:"rss_bytes"`
+}
+
+// ProcessUsage returns the CPU and memory usage of a detached process,
+// including the children in its process group where the platform allows
+func ProcessUsage(pid int) (*Usage, error) {
+	return processUsage(pid)
+}
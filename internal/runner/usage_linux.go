@@ -0,0 +1,110 @@
+//go:build linux
+
+package runner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// clockTicks is USER_HZ, the unit of the CPU times in /proc/<pid>/stat. It is
+// 100 on every mainstream Linux architecture.
+const clockTicks = 100
+
+// processUsage sums /proc/<pid>/stat and /proc/<pid>/statm over every process
+// in the group led by pid. Detached tasks get their own process group.
+func processUsage(pid int) (*Usage, error) {
+	uptime, err := systemUptime()
+	if err != nil {
+		return nil, err
+	}
+
+	statFiles, err := filepath.Glob("/proc/[0-9]*/stat")
+	if err != nil {
+		return nil, err
+	}
+
+	usage := &Usage{}
+	found := false
+	for _, statFile := range statFiles {
+		stat, err := readProcStat(statFile)
+		if err != nil || (stat.pid != pid && stat.pgrp != pid) {
+			continue
+		}
+		found = true
+
+		elapsed := uptime - float64(stat.startTime)/clockTicks
+		if elapsed > 0 {
+			usage.CPUPercent += float64(stat.cpuTicks) / clockTicks / elapsed * 100
+		}
+
+		statm, err := os.ReadFile(filepath.Join(filepath.Dir(statFile), "statm"))
+		if err != nil {
+			continue
+		}
+		if fields := strings.Fields(string(statm)); len(fields) > 1 {
+			pages, _ := strconv.ParseInt(fields[1], 10, 64)
+			usage.RSSBytes += pages * int64(os.Getpagesize())
+		}
+	}
+
+	if !found {
+		return nil, fmt.Errorf("process %d not found", pid)
+	}
+	return usage, nil
+}
+
+// procStat holds the fields of /proc/<pid>/stat used for usage reporting
+type procStat struct {
+	pid       int
+	pgrp      int
+	cpuTicks  int64
+	startTime int64
+}
+
+// readProcStat parses a /proc/<pid>/stat file. The command name may contain
+// spaces, so fields are counted from the closing parenthesis.
+func readProcStat(path string) (*procStat, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	line := string(data)
+	open := strings.IndexByte(line, '(')
+	end := strings.LastIndexByte(line, ')')
+	if open < 0 || end < open {
+		return nil, fmt.Errorf("malformed %s", path)
+	}
+
+	// fields[0] is field 3 (state) of proc(5)
+	fields := strings.Fields(line[end+1:])
+	if len(fields) < 20 {
+		return nil, fmt.Errorf("malformed %s", path)
+	}
+
+	stat := &procStat{}
+	stat.pid, _ = strconv.Atoi(strings.TrimSpace(line[:open]))
+	stat.pgrp, _ = strconv.Atoi(fields[2])
+	utime, _ := strconv.ParseInt(fields[11], 10, 64)
+	stime, _ := strconv.ParseInt(fields[12], 10, 64)
+	stat.cpuTicks = utime + stime
+	stat.startTime, _ = strconv.ParseInt(fields[19], 10, 64)
+	return stat, nil
+}
+
+// systemUptime returns the seconds since boot from /proc/uptime
+func systemUptime() (float64, error) {
+	data, err := os.ReadFile("/proc/uptime")
+	if err != nil {
+		return 0, err
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("malformed /proc/uptime")
+	}
+	return strconv.ParseFloat(fields[0], 64)
+}
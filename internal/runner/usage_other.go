@@ -0,0 +1,27 @@
+//go:build !linux && !windows
+
+package runner
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// processUsage asks ps for the CPU and resident memory of the process
+func processUsage(pid int) (*Usage, error) {
+	output, err := exec.Command("ps", "-o", "%cpu=,rss=", "-p", strconv.Itoa(pid)).Output()
+	if err != nil {
+		return nil, fmt.Errorf("process %d not found", pid)
+	}
+
+	fields := strings.Fields(string(output))
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("unexpected ps output: %q", output)
+	}
+
+	cpu, _ := strconv.ParseFloat(fields[0], 64)
+	rssKB, _ := strconv.ParseInt(fields[1], 10, 64)
+	return &Usage{CPUPercent: cpu, RSSBytes: rssKB * 1024}, nil
+}
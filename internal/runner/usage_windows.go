@@ -0,0 +1,34 @@
+//go:build windows
+
+package runner
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// processUsage reads the memory usage of the process from tasklist. tasklist
+// has no CPU percentage, so CPUPercent is -1.
+func processUsage(pid int) (*Usage, error) {
+	output, err := exec.Command("tasklist", "/FI", fmt.Sprintf("PID eq %d", pid), "/FO", "CSV", "/NH").Output()
+	if err != nil {
+		return nil, err
+	}
+
+	// "name.exe","1234","Console","1","12,345 K"
+	record, err := csv.NewReader(strings.NewReader(string(output))).Read()
+	if err != nil || len(record) < 5 {
+		return nil, fmt.Errorf("process %d not found", pid)
+	}
+
+	memory := strings.NewReplacer(",", "", ".", "", " K", "", " ", "").Replace(record[4])
+	rssKB, err := strconv.ParseInt(strings.TrimSpace(memory), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("unexpected tasklist memory value %q", record[4])
+	}
+
+	return &Usage{CPUPercent: -1, RSSBytes: rssKB * 1024}, nil
+}
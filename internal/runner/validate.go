@@ -0,0 +1,162 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Validate checks the config for problems that would otherwise only show up
+// mid-run and returns all of them together. On top of the checks done when
+// a config is loaded, it reports deps and task references that name no
+// task, which is only meaningful once configs are merged, so the cmd package
+// runs it after merging and applying the profile. Global tasks may refer to
+// tasks only some projects define, so their references aren't checked.
+func (c *Config) Validate() error {
+	errs := c.checkTasks()
+	for _, taskName := range c.sortedTaskNames() {
+		task := c.Tasks[taskName]
+		if task.Global {
+			continue
+		}
+		for _, dep := range task.Deps.Names() {
+			errs = append(errs, c.checkReference(taskName, "dep", dep))
+		}
+		for _, command := range commandsOf(task) {
+			if command.Task != "" {
+				errs = append(errs, c.checkReference(taskName, "task reference", command.Task))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// EmptyTasks returns the tasks without any cmds, deps or hooks. They are
+// valid, as placeholders, but more often a mistake, so :doctor warns of them.
+func (c *Config) EmptyTasks() []string {
+	var names []string
+	for _, taskName := range c.sortedTaskNames() {
+		task := c.Tasks[taskName]
+		if len(task.Cmds) == 0 && len(task.Deps) == 0 && len(task.Before) == 0 && len(task.After) == 0 {
+			names = append(names, taskName)
+		}
+	}
+	return names
+}
+
+// checkTasks returns the problems found in the config on its own that keep
+// a task from running: malformed timeouts and shell args and commands with
+// conflicting fields
+func (c *Config) checkTasks() []error {
+	var errs []error
+	if err := checkShellArgs("sh", c.ShellArgs); err != nil {
+		errs = append(errs, fmt.Errorf("shell_args: %w", err))
+	}
+	if err := checkShellArgs("powershell", c.PowerShellArgs); err != nil {
+		errs = append(errs, fmt.Errorf("powershell_args: %w", err))
+	}
+	if _, err := parseTimeout(c.Timeout); err != nil {
+		errs = append(errs, fmt.Errorf("timeout: %w", err))
+	}
+	for _, list := range []struct {
+		name     string
+		commands []Command
+	}{{"before_task", c.BeforeTask}, {"after_task", c.AfterTask}} {
+		for _, command := range list.commands {
+			if err := checkCommand(command); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", list.name, err))
+			}
+		}
+	}
+
+	for _, taskName := range c.sortedTaskNames() {
+		task := c.Tasks[taskName]
+		fail := func(format string, args ...any) {
+			errs = append(errs, fmt.Errorf("task %s: %s", taskName, fmt.Sprintf(format, args...)))
+		}
+
+		if _, err := parseTimeout(task.Timeout); err != nil {
+			fail("timeout: %v", err)
+		}
+		if _, err := parseTimeout(task.IdleTimeout); err != nil {
+			fail("idle_timeout: %v", err)
+		}
+		for _, command := range commandsOf(task) {
+			if err := checkCommand(command); err != nil {
+				fail("%v", err)
+			}
+		}
+		if task.Parallel {
+			for _, command := range task.Cmds {
+				if command.Capture != "" {
+					fail("capture can't be used in a parallel task")
+					break
+				}
+			}
+		}
+	}
+	return errs
+}
+
+// checkCommand reports a command whose fields contradict each other or
+// can't be parsed
+func checkCommand(command Command) error {
+	set := 0
+	for _, isSet := range []bool{command.Cmd != "", len(command.Exec) > 0, command.Task != ""} {
+		if isSet {
+			set++
+		}
+	}
+	switch {
+	case set > 1:
+		return fmt.Errorf("command %q sets more than one of cmd, exec and task", command.Line())
+	case len(command.Exec) > 0 && command.Exec[0] == "":
+		return fmt.Errorf("command %q has an empty program name", command.Line())
+	case command.Stdin != "" && command.StdinFile != "":
+		return fmt.Errorf("command %q sets both stdin and stdin_file", command.Line())
+	case command.Task != "" && command.Dir != "":
+		return fmt.Errorf("task reference %q can't set dir, its own commands set theirs", command.Task)
+	}
+
+	if _, err := parseTimeout(command.Timeout); err != nil {
+		return fmt.Errorf("command %q: %w", command.Line(), err)
+	}
+	return nil
+}
+
+// checkReference reports a dep or task reference naming a task the config
+// doesn't define. Names built from variables are only known at run time,
+// so they are not checked.
+func (c *Config) checkReference(taskName string, kind string, name string) error {
+	if strings.Contains(name, "{{") {
+		return nil
+	}
+	if _, exists := c.Tasks[name]; exists {
+		return nil
+	}
+
+	err := fmt.Errorf("task %s: %s %q is not a task", taskName, kind, name)
+	if suggestions := SuggestTasks(c, name); len(suggestions) > 0 {
+		err = fmt.Errorf("%w, did you mean %q?", err, suggestions[0])
+	}
+	return err
+}
+
+// commandsOf returns every command of a task: before, cmds and after
+func commandsOf(task Task) []Command {
+	commands := append([]Command{}, task.Before...)
+	commands = append(commands, task.Cmds...)
+	return append(commands, task.After...)
+}
+
+// sortedTaskNames returns the task names in alphabetical order, so problems
+// are reported in a stable order
+func (c *Config) sortedTaskNames() []string {
+	names := make([]string, 0, len(c.Tasks))
+	for name := range c.Tasks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
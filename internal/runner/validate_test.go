@@ -0,0 +1,28 @@
+package runner
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateSkipsGlobalReferences(t *testing.T) {
+	config, err := LoadConfigReader(strings.NewReader(`
+tasks:
+  ci:
+    deps: [lint]
+  release:
+    cmds: [{task: publsh}]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ci := config.Tasks["ci"]
+	ci.Global = true
+	config.Tasks["ci"] = ci
+
+	err = config.Validate()
+	if err == nil || strings.Contains(err.Error(), "lint") || !strings.Contains(err.Error(), `task reference "publsh" is not a task`) {
+		t.Fatalf("got %v, want only the project task's reference reported", err)
+	}
+}
@@ -0,0 +1,48 @@
+package runner
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os/exec"
+	"strings"
+	"text/template"
+)
+
+// checkWhen reports whether the when condition of a task holds. The
+// condition is expanded like a command, with unset vars expanding to an
+// empty string. An empty result, false, no or 0 doesn't hold and true, yes
+// or 1 does, ignoring case and surrounding space. Anything else runs as a
+// shell command and holds when it exits with 0.
+func (r *Runner) checkWhen(taskName string, task Task, vars map[string]string) (bool, error) {
+	tmpl, err := template.New("when").Option("missingkey=zero").Funcs(r.templateFuncs()).Parse(task.When)
+	if err != nil {
+		return false, err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, vars); err != nil {
+		return false, err
+	}
+
+	condition := strings.TrimSpace(buf.String())
+	switch strings.ToLower(condition) {
+	case "", "false", "no", "0":
+		return false, nil
+	case "true", "yes", "1":
+		return true, nil
+	}
+
+	cmd := r.shellCommand(r.context(), condition)
+	// Only the exit code counts, but errors help to debug the condition
+	cmd.Stdout = io.Discard
+	cmd.Stderr = r.taskErrOut(taskName)
+	r.setCommandEnv(cmd)
+	r.setCommandCancel(cmd)
+
+	err = cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return false, nil
+	}
+	return err == nil, err
+}
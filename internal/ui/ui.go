@@ -0,0 +1,144 @@
+// Package ui centralizes how t decorates its own console messages.
+//
+// Messages are written with emoji markers. When symbols are disabled with
+// --color=never, NO_COLOR or a non-terminal stdout, Stdout replaces them with
+// plain ASCII markers such as [RUN] and [OK].
+package ui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Stdout is the writer for t's own messages
+var Stdout io.Writer = os.Stdout
+
+// CommandStdout is the writer for the output of the commands t runs, which
+// is printed as is
+var CommandStdout io.Writer = os.Stdout
+
+// plain reports whether emoji markers are replaced with ASCII
+var plain bool
+
+// symbols maps every emoji marker used in messages to its ASCII replacement
+var symbols = []string{
+	"🔧", "[RUN]",
+	"➡️", "[CMD]",
+	"✅", "[OK]",
+	"❌", "[ERR]",
+	"⚠️", "[WARN]",
+	"💡", "[TIP]",
+	"🛑", "[STOP]",
+	"📝", "[NOTE]",
+	"📋", "[LIST]",
+	"🔗", "[LINK]",
+	"📁", "[GROUP]",
+	"🙈", "[HIDDEN]",
+	"🤔", "[INPUT]",
+	"🎉", "[DONE]",
+	"🧮", "[MATRIX]",
+	"👋", "[DETACH]",
+	"🏁", "[EXIT]",
+	"🌐", "[REMOTE]",
+	"📖", "[DESC]",
+	"🏷️", "[LABELS]",
+	"📦", "[VARS]",
+	"📄", "[FILE]",
+	"📡", "[FOLLOW]",
+	"⏱️", "[TIME]",
+	"📭", "[EMPTY]",
+	"🆔", "[PID]",
+	"⏰", "[UPTIME]",
+	"📊", "[SUMMARY]",
+	"🚀", "[START]",
+	"🩺", "[DOCTOR]",
+	"🔁", "[REPEAT]",
+	"🆕", "[NEW]",
+	"🧰", "[REQUIRES]",
+	"📈", "[USAGE]",
+	"🧭", "[PLAN]",
+	"📨", "[SIGNAL]",
+	"📜", "[HISTORY]",
+	"⏭️", "[SKIP]",
+	"⏳", "[WAIT]",
+	"⏸️", "[PAUSE]",
+	"▶️", "[RESUME]",
+}
+
+// Configure selects the output style for the given --color mode: "always"
+// keeps emoji, "never" uses ASCII markers and "auto" uses emoji only when
+// stdout is a terminal and NO_COLOR is not set.
+func Configure(mode string) error {
+	switch mode {
+	case "always":
+		plain = false
+	case "never":
+		plain = true
+	case "auto", "":
+		plain = os.Getenv("NO_COLOR") != "" || !isTerminal(os.Stdout)
+	default:
+		return fmt.Errorf("invalid --color value %q (expected auto, always or never)", mode)
+	}
+
+	if plain {
+		Stdout = plainWriter{os.Stdout}
+	} else {
+		Stdout = os.Stdout
+	}
+
+	return nil
+}
+
+// Plain reports whether emoji markers are replaced with ASCII
+func Plain() bool {
+	return plain
+}
+
+// replaceMarker replaces an emoji marker at the start of a line, after any
+// indentation, and the extra space emoji are followed by for their width.
+// Markers elsewhere are left alone so that user content such as echoed
+// commands is printed unchanged.
+func replaceMarker(line string) string {
+	rest := strings.TrimLeft(line, " \t")
+	indent := line[:len(line)-len(rest)]
+	for i := 0; i < len(symbols); i += 2 {
+		if strings.HasPrefix(rest, symbols[i]) {
+			text := strings.TrimLeft(rest[len(symbols[i]):], " ")
+			if text != "" && text != "\n" {
+				text = " " + text
+			}
+			return indent + symbols[i+1] + text
+		}
+	}
+	return line
+}
+
+// plainWriter replaces emoji markers in everything written through it. Each
+// write is one of t's messages, so a marker is replaced at its start even
+// when it continues a line, such as the answer printed after a prompt.
+type plainWriter struct {
+	out io.Writer
+}
+
+func (w plainWriter) Write(p []byte) (int, error) {
+	lines := strings.SplitAfter(string(p), "\n")
+	for i, line := range lines {
+		lines[i] = replaceMarker(line)
+	}
+
+	if _, err := io.WriteString(w.out, strings.Join(lines, "")); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// isTerminal reports whether the file is attached to a terminal
+func isTerminal(file *os.File) bool {
+	stat, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return stat.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,25 @@
+package ui
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestPlainWriterReplacesMarkersOfEveryMessage(t *testing.T) {
+	var out bytes.Buffer
+	w := plainWriter{&out}
+
+	// A prompt leaves its line open for the answer, which is then echoed
+	fmt.Fprint(w, "📝 Project name (required): ")
+	fmt.Fprintf(w, "✅ %s: %s\n", "project", "proj")
+	fmt.Fprintf(w, "➡️  %s\n", "echo ✅ kept")
+	fmt.Fprint(w, "   ❌ linux: failed\n⚠️  two\nlines\n")
+
+	want := "[NOTE] Project name (required): [OK] project: proj\n" +
+		"[CMD] echo ✅ kept\n" +
+		"   [ERR] linux: failed\n[WARN] two\nlines\n"
+	if out.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", out.String(), want)
+	}
+}